@@ -0,0 +1,323 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// typeFields are the pattern fields that only have an effect with
+// AnalyzeTypes.
+var typeFields = []string{"pkg", "alias", "allow_only", "implements"}
+
+// typeKinds are the kinds of patterns that never match without
+// AnalyzeTypes.
+var typeKinds = []string{kindConstruct, kindCompare, kindAssert, kindSignature, kindConstant, kindLayer}
+
+// maxExpansions limits the number of texts that a regular expression may
+// match for the check whether it is shadowed by another one.
+const maxExpansions = 100
+
+// Check looks for patterns which are probably not what was intended:
+// duplicates, patterns that are shadowed by others, options that get
+// ignored without type information and regular expressions for identifiers
+// which match too much or nothing at all. Each problem is described by one
+// message.
+func (l *Linter) Check() []string {
+	var problems []string
+	report := func(p *pattern, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("pattern `%s`: ", p.description())+fmt.Sprintf(format, args...))
+	}
+
+	keys := make([]string, len(l.patterns))
+	for i, p := range l.patterns {
+		keys[i] = p.key()
+	}
+	for j, p := range l.patterns {
+		for i := 0; i < j; i++ {
+			if keys[i] == keys[j] {
+				report(p, "duplicate of pattern %d", i+1)
+				break
+			}
+		}
+	}
+	for j, p := range l.patterns {
+		for i, other := range l.patterns {
+			if i == j || keys[i] == keys[j] || !other.shadows(p) || (i > j && p.shadows(other)) {
+				continue
+			}
+			report(p, "shadowed by `%s`, which matches everything that it matches", other.description())
+			break
+		}
+	}
+
+	for _, p := range l.patterns {
+		switch {
+		case l.cfg.AnalyzeTypes:
+		case slices.Contains(typeKinds, p.kind()):
+			report(p, "%s patterns require analyze_types", p.kind())
+		default:
+			for _, field := range p.optionalFields() {
+				if field.set && slices.Contains(typeFields, field.name) {
+					report(p, "%s is ignored without analyze_types", field.name)
+				}
+			}
+		}
+	}
+
+	if l.cfg.AnalyzeTypes {
+		imp := importer.ForCompiler(token.NewFileSet(), "source", nil)
+		for _, p := range l.patterns {
+			if p.Implements != "" {
+				object, err := resolveImported(imp, p.Implements)
+				if err == nil {
+					_, err = asInterface(object)
+				}
+				if err != nil {
+					report(p, "implements `%s` cannot be resolved: %s", p.Implements, err)
+				}
+			}
+			if p.Const != "" {
+				object, err := resolveImported(imp, p.Const)
+				if _, ok := object.(*types.Const); err == nil && !ok {
+					err = fmt.Errorf("not a constant")
+				}
+				if err != nil {
+					report(p, "const `%s` cannot be resolved: %s", p.Const, err)
+				}
+			}
+		}
+	}
+
+	for _, p := range l.patterns {
+		if p.kind() != kindIdentifier || p.Pattern == "" {
+			continue
+		}
+		re, err := syntax.Parse(p.Pattern, syntax.Perl)
+		if err != nil {
+			continue
+		}
+		if hasLiteral(re, " \t\n") && !strings.ContainsAny(p.Pattern, "{}") {
+			report(p, "contains whitespace, which never occurs in identifiers")
+		}
+		if hasSingleAnyChar(re) {
+			report(p, "contains `.` which matches any character, use `\\.` for a dot")
+		}
+		switch begins, ends := isAnchored(re, true), isAnchored(re, false); {
+		case !begins && !ends:
+			report(p, "is not anchored with ^ and $, so it also matches identifiers that contain it")
+		case !begins:
+			report(p, "is not anchored with ^, so it also matches identifiers that end with it")
+		}
+	}
+	return problems
+}
+
+// resolveImported finds an object for a name in a pattern like during
+// linting, except that packages get imported with the importer because
+// there is no analyzed package which imports them.
+func resolveImported(imp types.Importer, name string) (types.Object, error) {
+	return resolveObject(name, func(path string) (*types.Package, error) {
+		pkg, err := imp.Import(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot import package %s", path)
+		}
+		return pkg, nil
+	})
+}
+
+// key identifies patterns which match the same code, regardless of their
+// message and ID.
+func (p *pattern) key() string {
+	other := *p
+	other.Msg, other.ID = "", ""
+	data, _ := yaml.Marshal(&other)
+	return string(data)
+}
+
+// shadows checks whether the pattern reports everything that the other
+// pattern reports. This is only detected for identifier patterns where the
+// regular expression of the other one only matches a few texts and the
+// pattern has no further restrictions.
+func (p *pattern) shadows(other *pattern) bool {
+	if p.kind() != kindIdentifier || other.kind() != kindIdentifier || other.AllowOnly {
+		return false
+	}
+	// Apart from the regular expression, the pattern may only restrict
+	// matches in the same way as the other one.
+	restricted := *p
+	restricted.Pattern, restricted.Kind = "", ""
+	if restricted.Package == other.Package {
+		restricted.Package = ""
+	}
+	if restricted.Alias == other.Alias {
+		restricted.Alias = ""
+	}
+	if restricted.key() != (&pattern{}).key() {
+		return false
+	}
+	re, err := syntax.Parse(other.Pattern, syntax.Perl)
+	if err != nil || !isAnchored(re, true) || !isAnchored(re, false) {
+		return false
+	}
+	texts, ok := expand(re)
+	if !ok {
+		return false
+	}
+	for _, text := range texts {
+		if !p.re.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// isComment checks whether the regular expression is a comment like
+// `(# message)`, which gets extracted by extractComment.
+func isComment(re *syntax.Regexp) bool {
+	return commentPrefix.MatchString(re.String())
+}
+
+// commentPrefix matches the beginning of a comment, which may be wrapped in
+// groups with flags like `(?-s:(# message))`.
+var commentPrefix = regexp.MustCompile(`^(\((\?[a-zA-Z-]*:)?)*#`)
+
+// isAnchored checks whether all texts that the regular expression matches
+// must begin (or end) at the beginning (or end) of the text.
+func isAnchored(re *syntax.Regexp, begin bool) bool {
+	switch re.Op {
+	case syntax.OpBeginText, syntax.OpBeginLine:
+		return begin
+	case syntax.OpEndText, syntax.OpEndLine:
+		return !begin
+	case syntax.OpCapture:
+		return isAnchored(re.Sub[0], begin)
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !isAnchored(sub, begin) {
+				return false
+			}
+		}
+		return len(re.Sub) > 0
+	case syntax.OpConcat:
+		subs := re.Sub
+		for len(subs) > 0 && isComment(subs[len(subs)-1]) {
+			subs = subs[:len(subs)-1]
+		}
+		if len(subs) == 0 {
+			return false
+		}
+		if begin {
+			return isAnchored(subs[0], begin)
+		}
+		return isAnchored(subs[len(subs)-1], begin)
+	default:
+		return false
+	}
+}
+
+// hasLiteral checks whether the regular expression contains one of the
+// characters as literal outside of comments.
+func hasLiteral(re *syntax.Regexp, chars string) bool {
+	if isComment(re) {
+		return false
+	}
+	if re.Op == syntax.OpLiteral && strings.ContainsAny(string(re.Rune), chars) {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasLiteral(sub, chars) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSingleAnyChar checks whether the regular expression contains a `.`
+// which isn't repeated, as in `fmt.Println`, where `\.` was most likely
+// intended.
+func hasSingleAnyChar(re *syntax.Regexp) bool {
+	if isComment(re) {
+		return false
+	}
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		return false
+	}
+	for _, sub := range re.Sub {
+		if hasSingleAnyChar(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns all texts that a regular expression matches, provided that
+// these are only a few. Anchors and comments are ignored.
+func expand(re *syntax.Regexp) ([]string, bool) {
+	if isComment(re) {
+		return []string{""}, true
+	}
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginText, syntax.OpEndText, syntax.OpBeginLine, syntax.OpEndLine:
+		return []string{""}, true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil, false
+		}
+		return []string{string(re.Rune)}, true
+	case syntax.OpCharClass:
+		var texts []string
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if len(texts) >= maxExpansions {
+					return nil, false
+				}
+				texts = append(texts, string(r))
+			}
+		}
+		return texts, true
+	case syntax.OpCapture:
+		return expand(re.Sub[0])
+	case syntax.OpQuest:
+		texts, ok := expand(re.Sub[0])
+		return append(texts, ""), ok
+	case syntax.OpAlternate:
+		var texts []string
+		for _, sub := range re.Sub {
+			subTexts, ok := expand(sub)
+			if !ok || len(texts)+len(subTexts) > maxExpansions {
+				return nil, false
+			}
+			texts = append(texts, subTexts...)
+		}
+		return texts, true
+	case syntax.OpConcat:
+		texts := []string{""}
+		for _, sub := range re.Sub {
+			subTexts, ok := expand(sub)
+			if !ok || len(texts)*len(subTexts) > maxExpansions {
+				return nil, false
+			}
+			combined := make([]string, 0, len(texts)*len(subTexts))
+			for _, prefix := range texts {
+				for _, suffix := range subTexts {
+					combined = append(combined, prefix+suffix)
+				}
+			}
+			texts = combined
+		}
+		return texts, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,106 @@
+package forbidigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		patterns     []string
+		analyzeTypes bool
+		problems     []string
+	}{
+		{
+			name: "default patterns",
+		},
+		{
+			name:     "duplicate",
+			patterns: []string{`^fmt\.Println$`, `{p: ^fmt\.Println$, msg: use a logger}`},
+			problems: []string{"pattern `^fmt\\.Println$`: duplicate of pattern 1"},
+		},
+		{
+			name:     "shadowed",
+			patterns: []string{`^fmt\.Print(ln|f)?$`, `^fmt\.Print`, `{p: ^fmt\.Println$, usage: call}`, `{p: ^fmt\.Print$, pkg: ^fmt$}`},
+			problems: []string{
+				"pattern `^fmt\\.Print(ln|f)?$`: shadowed by `^fmt\\.Print`, which matches everything that it matches",
+				"pattern `^fmt\\.Println$`: shadowed by `^fmt\\.Print(ln|f)?$`, which matches everything that it matches",
+				"pattern `^fmt\\.Print$`: shadowed by `^fmt\\.Print(ln|f)?$`, which matches everything that it matches",
+				"pattern `^fmt\\.Print$`: pkg is ignored without analyze_types",
+			},
+		},
+		{
+			name:     "not shadowed by more restrictive patterns",
+			patterns: []string{`{p: ^fmt\.Print, usage: call}`, `^fmt\.Println$`, `{p: ^os\., pkg: ^os$}`, `^os\.Exit$`},
+			problems: []string{
+				"pattern `^os\\.`: pkg is ignored without analyze_types",
+			},
+		},
+		{
+			name:         "type information",
+			patterns:     []string{`{p: ^os\.Getenv$, pkg: ^os$}`, `{kind: construct, p: ^sync\.Mutex$}`},
+			analyzeTypes: true,
+		},
+		{
+			name: "implements",
+			patterns: []string{
+				`{p: ^.*\.Close$, implements: io.Closer}`,
+				`{p: ^.*\.Error$, implements: error}`,
+				`{p: ^.*\.Read$, implements: io.Rider}`,
+				`{p: ^.*\.Write$, implements: io.EOF}`,
+				`{p: ^.*\.Run$, implements: example.com/missing.Runner}`,
+				`{p: ^.*\.String$, implements: Stringer}`,
+			},
+			analyzeTypes: true,
+			problems: []string{
+				"pattern `^.*\\.Read$`: implements `io.Rider` cannot be resolved: package io has no Rider",
+				"pattern `^.*\\.Write$`: implements `io.EOF` cannot be resolved: not an interface",
+				"pattern `^.*\\.Run$`: implements `example.com/missing.Runner` cannot be resolved: cannot import package example.com/missing",
+				"pattern `^.*\\.String$`: implements `Stringer` cannot be resolved: unknown name without package",
+			},
+		},
+		{
+			name: "const",
+			patterns: []string{
+				`{kind: constant, const: math.MaxInt8}`,
+				`{kind: constant, const: true}`,
+				`{kind: constant, const: math.MaxInt7}`,
+				`{kind: constant, const: math.Sqrt}`,
+				`{kind: constant, const: MaxInt8}`,
+			},
+			analyzeTypes: true,
+			problems: []string{
+				"pattern `{const: math.MaxInt7}`: const `math.MaxInt7` cannot be resolved: package math has no MaxInt7",
+				"pattern `{const: math.Sqrt}`: const `math.Sqrt` cannot be resolved: not a constant",
+				"pattern `{const: MaxInt8}`: const `MaxInt8` cannot be resolved: unknown name without package",
+			},
+		},
+		{
+			name:     "no type information",
+			patterns: []string{`{p: ^os\.Getenv$, pkg: ^os$}`, `{kind: construct, p: ^sync\.Mutex$}`},
+			problems: []string{
+				"pattern `^os\\.Getenv$`: pkg is ignored without analyze_types",
+				"pattern `^sync\\.Mutex$`: construct patterns require analyze_types",
+			},
+		},
+		{
+			name:     "regular expressions",
+			patterns: []string{`fmt.Errorf`, `errors\.New$`, `^errors\.Is(# use errors.As)?`, `^foo bar$`, `^ginkgo\.F.*`},
+			problems: []string{
+				"pattern `fmt.Errorf`: contains `.` which matches any character, use `\\.` for a dot",
+				"pattern `fmt.Errorf`: is not anchored with ^ and $, so it also matches identifiers that contain it",
+				"pattern `errors\\.New$`: is not anchored with ^, so it also matches identifiers that end with it",
+				"pattern `^foo bar$`: contains whitespace, which never occurs in identifiers",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			linter, err := NewLinter(tc.patterns, OptionAnalyzeTypes(tc.analyzeTypes))
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.problems, linter.Check())
+		})
+	}
+}
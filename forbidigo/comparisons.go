@@ -0,0 +1,125 @@
+package forbidigo
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// checkComparison matches the types of the operands of `==` and `!=`
+// against the patterns for compare. Comparisons with nil are allowed.
+func (v *visitor) checkComparison(expr *ast.BinaryExpr) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+	if v.isNil(expr.X) || v.isNil(expr.Y) {
+		return
+	}
+	matchTexts := append(v.exprTypeTexts(expr.X), v.exprTypeTexts(expr.Y)...)
+	v.reportTypes(kindCompare, expr, v.textFor(expr), matchTexts)
+}
+
+// checkSwitchComparisons treats each case of a switch statement with a tag
+// like a comparison of the tag with the case expression.
+func (v *visitor) checkSwitchComparisons(stmt *ast.SwitchStmt) {
+	if stmt.Tag == nil || stmt.Body == nil {
+		return
+	}
+	tagTexts := v.exprTypeTexts(stmt.Tag)
+	for _, clause := range stmt.Body.List {
+		clause, ok := clause.(*ast.CaseClause)
+		if !ok {
+			continue
+		}
+		for _, expr := range clause.List {
+			if v.isNil(expr) || v.isNil(stmt.Tag) {
+				continue
+			}
+			matchTexts := append(append([]matchText{}, tagTexts...), v.exprTypeTexts(expr)...)
+			v.reportTypes(kindCompare, expr, "case "+v.textFor(expr), matchTexts)
+		}
+	}
+}
+
+// checkTypeAssertion matches the type of the operand and the asserted type
+// against the patterns for assert.
+func (v *visitor) checkTypeAssertion(expr *ast.TypeAssertExpr) {
+	if expr.Type == nil {
+		// Part of a type switch, handled by checkTypeSwitch.
+		return
+	}
+	matchTexts := append(v.exprTypeTexts(expr.X), v.exprTypeTexts(expr.Type)...)
+	v.reportTypes(kindAssert, expr, v.textFor(expr), matchTexts)
+}
+
+// checkTypeSwitch treats each case of a type switch like a type assertion.
+func (v *visitor) checkTypeSwitch(stmt *ast.TypeSwitchStmt) {
+	var assert *ast.TypeAssertExpr
+	switch s := stmt.Assign.(type) {
+	case *ast.AssignStmt:
+		if len(s.Rhs) == 1 {
+			assert, _ = s.Rhs[0].(*ast.TypeAssertExpr)
+		}
+	case *ast.ExprStmt:
+		assert, _ = s.X.(*ast.TypeAssertExpr)
+	}
+	if assert == nil || stmt.Body == nil {
+		return
+	}
+	operandTexts := v.exprTypeTexts(assert.X)
+	for _, clause := range stmt.Body.List {
+		clause, ok := clause.(*ast.CaseClause)
+		if !ok {
+			continue
+		}
+		for _, expr := range clause.List {
+			if v.isNil(expr) {
+				continue
+			}
+			matchTexts := append(append([]matchText{}, operandTexts...), v.exprTypeTexts(expr)...)
+			v.reportTypes(kindAssert, expr, "case "+v.textFor(expr), matchTexts)
+		}
+	}
+}
+
+// exprTypeTexts returns the texts for the type of an expression.
+func (v *visitor) exprTypeTexts(expr ast.Expr) []matchText {
+	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil {
+		return nil
+	}
+	t := v.runConfig.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return nil
+	}
+	return v.typeTexts(t)
+}
+
+func (v *visitor) isNil(expr ast.Expr) bool {
+	if v.runConfig.TypesInfo == nil {
+		return false
+	}
+	return v.runConfig.TypesInfo.Types[expr].IsNil()
+}
+
+// reportTypes matches the type texts against the patterns of the given kind.
+func (v *visitor) reportTypes(kind string, node ast.Node, srcText string, matchTexts []matchText) {
+	if len(matchTexts) == 0 {
+		return
+	}
+	v.runConfig.DebugLog("%s: %s %v", v.runConfig.Fset.Position(node.Pos()), kind, matchTexts)
+	for _, p := range v.linter.patterns {
+		if p.kind() != kind ||
+			!p.matches(matchTexts) ||
+			!v.matchesEnclosingFunc(p) ||
+			!v.matchesContext(p) ||
+			v.permitText(node.Pos(), srcText) {
+			continue
+		}
+		v.issues = append(v.issues, UsedIssue{
+			identifier: srcText,
+			pattern:    p.re.String(),
+			pos:        node.Pos(),
+			position:   v.runConfig.Fset.Position(node.Pos()),
+			customMsg:  p.Msg,
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package forbidigo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the configuration files that get used
+// automatically when they are found in the directory of a package or one
+// of its parents inside the module.
+const ConfigFileName = ".forbidigo.yml"
+
+// Config is the content of a configuration file in YAML or JSON format.
+// Options which are not set in the file are nil.
+type Config struct {
+	// Extends lists the configurations which this one is based on, either
+	// the names of presets or paths of files relative to this one. Files
+	// are recognized by their extension (.yml, .yaml or .json) or a path
+	// separator.
+	Extends []string `yaml:"extends,omitempty"`
+
+	// Patterns are regular expressions or pattern structs, like the
+	// patterns that can be passed to NewLinter.
+	Patterns []yamlPattern `yaml:"patterns,omitempty"`
+
+	// Layers are the layers of the architecture, each of them equivalent
+	// to a pattern with `kind: layer`.
+	Layers []Layer `yaml:"layers,omitempty"`
+
+	// Disable lists the IDs of patterns and the names of layers which are
+	// configured for a parent directory and don't apply to the directory of
+	// this file.
+	Disable []string `yaml:"disable,omitempty"`
+
+	// Root stops merging with the configuration files of parent
+	// directories.
+	Root bool `yaml:"root,omitempty"`
+
+	// The following options correspond to the command line flags with the
+	// same name.
+	SetExitStatus          *bool `yaml:"set_exit_status,omitempty"`          // Set exit status to 1 if any issues are found.
+	Tests                  *bool `yaml:"tests,omitempty"`                    // Include tests.
+	ExcludeGodocExamples   *bool `yaml:"exclude_godoc_examples,omitempty"`   // Exclude code in godoc examples.
+	IgnorePermitDirectives *bool `yaml:"ignore_permit_directives,omitempty"` // Ignore `//permit` comments.
+	AnalyzeTypes           *bool `yaml:"analyze_types,omitempty"`            // Use type information to match what code refers to.
+	AnalyzeReflection      *bool `yaml:"analyze_reflection,omitempty"`       // Match methods and fields looked up by name via reflect.
+}
+
+// Layer is a group of packages which may only use certain other layers.
+type Layer struct {
+	// Name identifies the layer in Uses and in messages.
+	Name string `yaml:"name"`
+	// Pkg is a regular expression for the package paths in the layer.
+	Pkg string `yaml:"pkg"`
+	// Uses lists the names of the layers which the layer may use.
+	Uses []string `yaml:"uses,omitempty"`
+	// Msg replaces the default message for disallowed uses.
+	Msg string `yaml:"msg,omitempty"`
+}
+
+// LoadConfig reads a configuration file. Unknown fields are errors. The
+// configurations that it extends get loaded and merged with it.
+func LoadConfig(path string) (*Config, error) {
+	return loadConfig(path, nil)
+}
+
+// loadConfig reads a configuration file. Extends is resolved recursively,
+// with extending lists the files that are currently being loaded.
+func loadConfig(path string, extending []string) (*Config, error) {
+	if slices.Contains(extending, path) {
+		return nil, fmt.Errorf("%s: extends itself via %s", path, strings.Join(extending, ", "))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	config, err := decodeConfig(path, file)
+	if err != nil {
+		return nil, err
+	}
+	return config.extend(filepath.Dir(path), append(extending, path))
+}
+
+// decodeConfig parses a configuration. The name is used in errors, which
+// include the line and column for violations of the schema and invalid
+// patterns.
+func decodeConfig(name string, r io.Reader) (*Config, error) {
+	var node yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&node); err != nil {
+		if err == io.EOF {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	// Empty documents and properties without a value are treated as unset.
+	if len(node.Content) == 0 || node.Content[0].ShortTag() == "!!null" {
+		return &Config{}, nil
+	}
+	dropNulls(node.Content[0])
+	if errs := validateSchema(&node); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, fmt.Sprintf("%s:%s", name, err))
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+	// Decode the patterns one at a time to know where invalid ones are.
+	if root := node.Content[0]; root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "patterns" {
+				continue
+			}
+			for _, item := range root.Content[i+1].Content {
+				var p yamlPattern
+				if err := item.Decode(&p); err != nil {
+					return nil, fmt.Errorf("%s:%d:%d: %w", name, item.Line, item.Column, err)
+				}
+			}
+		}
+	}
+	var config Config
+	if err := node.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &config, nil
+}
+
+// dropNulls removes the properties with null values from the mappings in
+// the node, so that they are treated like properties which aren't set.
+func dropNulls(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i+1].ShortTag() != "!!null" {
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+		}
+		node.Content = content
+	}
+	for _, child := range node.Content {
+		dropNulls(child)
+	}
+}
+
+// extend merges the configurations listed in Extends, in that order, and
+// then the configuration itself. Files get resolved relative to dir.
+func (c *Config) extend(dir string, extending []string) (*Config, error) {
+	if len(c.Extends) == 0 {
+		return c, nil
+	}
+	base := &Config{}
+	for _, name := range c.Extends {
+		var extended *Config
+		var err error
+		if isConfigFile(name) {
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(dir, name)
+			}
+			extended, err = loadConfig(name, extending)
+		} else {
+			extended, err = loadPreset(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		base = base.merge(extended)
+	}
+	// Root refers to the parent directories, which are not involved here,
+	// and Disable also applies to the parent directories.
+	root := c.Root
+	c.Root = false
+	merged := base.merge(c)
+	merged.Extends, merged.Disable, merged.Root = c.Extends, c.Disable, root
+	return merged, nil
+}
+
+// isConfigFile checks whether an entry in Extends is a file instead of a
+// preset.
+func isConfigFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yml", ".yaml", ".json":
+		return true
+	}
+	return strings.ContainsAny(name, `/\`)
+}
+
+// LoadDirConfig loads the configuration for the packages in a directory. It
+// merges all ConfigFileName files from the root directory of the module
+// which contains the directory down to the directory itself, so files in
+// subdirectories add to and override the configuration of their parents
+// unless they set `root: true`. Outside of modules, only the directory
+// itself gets checked. The result is nil if there are no configuration
+// files.
+func LoadDirConfig(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{dir}
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			// Not inside a module.
+			dirs = dirs[:1]
+			break
+		}
+		dirs = append(dirs, parent)
+		current = parent
+	}
+
+	var config *Config
+	for i := len(dirs) - 1; i >= 0; i-- {
+		path := filepath.Join(dirs[i], ConfigFileName)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		child, err := LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			config = child
+		} else {
+			config = config.merge(child)
+		}
+	}
+	return config, nil
+}
+
+// merge returns the configuration for a subdirectory with its own
+// configuration file. Patterns and layers of the child replace those of the
+// parent with the same ID or name, respectively, or get added, and options
+// which are set in the child override those of the parent.
+func (c *Config) merge(child *Config) *Config {
+	if child.Root {
+		return child
+	}
+	removedPatterns, removedLayers := map[string]bool{}, map[string]bool{}
+	for _, name := range child.Disable {
+		removedPatterns[name], removedLayers[name] = true, true
+	}
+	for _, p := range child.Patterns {
+		if p.ID != "" {
+			removedPatterns[p.ID] = true
+		}
+	}
+	for _, layer := range child.Layers {
+		removedLayers[layer.Name] = true
+	}
+
+	merged := &Config{}
+	for _, p := range c.Patterns {
+		if p.ID == "" || !removedPatterns[p.ID] {
+			merged.Patterns = append(merged.Patterns, p)
+		}
+	}
+	merged.Patterns = append(merged.Patterns, child.Patterns...)
+	for _, layer := range c.Layers {
+		if !removedLayers[layer.Name] {
+			merged.Layers = append(merged.Layers, layer)
+		}
+	}
+	merged.Layers = append(merged.Layers, child.Layers...)
+
+	override := func(parent, child *bool) *bool {
+		if child != nil {
+			return child
+		}
+		return parent
+	}
+	merged.SetExitStatus = override(c.SetExitStatus, child.SetExitStatus)
+	merged.Tests = override(c.Tests, child.Tests)
+	merged.ExcludeGodocExamples = override(c.ExcludeGodocExamples, child.ExcludeGodocExamples)
+	merged.IgnorePermitDirectives = override(c.IgnorePermitDirectives, child.IgnorePermitDirectives)
+	merged.AnalyzeTypes = override(c.AnalyzeTypes, child.AnalyzeTypes)
+	merged.AnalyzeReflection = override(c.AnalyzeReflection, child.AnalyzeReflection)
+	return merged
+}
+
+// Options returns the linter options which are set in the configuration.
+func (c *Config) Options() []Option {
+	var options []Option
+	if c.ExcludeGodocExamples != nil {
+		options = append(options, OptionExcludeGodocExamples(*c.ExcludeGodocExamples))
+	}
+	if c.IgnorePermitDirectives != nil {
+		options = append(options, OptionIgnorePermitDirectives(*c.IgnorePermitDirectives))
+	}
+	if c.AnalyzeTypes != nil {
+		options = append(options, OptionAnalyzeTypes(*c.AnalyzeTypes))
+	}
+	if c.AnalyzeReflection != nil {
+		options = append(options, OptionAnalyzeReflection(*c.AnalyzeReflection))
+	}
+	return options
+}
+
+// NewLinter creates a linter for the patterns, layers and options in the
+// configuration plus the additional patterns. The additional options
+// override those from the configuration. Like with the NewLinter function,
+// the default patterns are used if there are no patterns at all.
+func (c *Config) NewLinter(patterns []string, options ...Option) (*Linter, error) {
+	parsed := make([]*pattern, 0, len(c.Patterns)+len(c.Layers))
+	for i := range c.Patterns {
+		parsed = append(parsed, (*pattern)(&c.Patterns[i]))
+	}
+	for _, layer := range c.Layers {
+		p := &pattern{Kind: kindLayer, Name: layer.Name, Package: layer.Pkg, Uses: layer.Uses, Msg: layer.Msg}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("layer `%s`: %w", layer.Name, err)
+		}
+		parsed = append(parsed, p)
+	}
+	if len(parsed) == 0 && len(patterns) == 0 {
+		patterns = DefaultPatterns()
+	}
+	return newLinter(parsed, patterns, append(c.Options(), options...)...)
+}
@@ -127,3 +127,35 @@ func OptionAnalyzeTypes(o bool) Option {
 		o: o,
 	}
 }
+
+type optionAnalyzeReflectionImpl struct {
+	o bool
+}
+
+func (o optionAnalyzeReflectionImpl) apply(c *config) error {
+	c.AnalyzeReflection = o.o
+	return nil
+}
+
+func (o optionAnalyzeReflectionImpl) Equal(v optionAnalyzeReflectionImpl) bool {
+	switch {
+	case !cmp.Equal(o.o, v.o):
+		return false
+	}
+	return true
+}
+
+func (o optionAnalyzeReflectionImpl) String() string {
+	name := "OptionAnalyzeReflection"
+
+	// hack to avoid go vet error about passing a function to Sprintf
+	var value interface{} = o.o
+	return fmt.Sprintf("%s: %+v", name, value)
+}
+
+// OptionAnalyzeReflection enable to match methods and fields that get looked up by name via reflect, requires AnalyzeTypes
+func OptionAnalyzeReflection(o bool) Option {
+	return optionAnalyzeReflectionImpl{
+		o: o,
+	}
+}
@@ -0,0 +1,215 @@
+package forbidigo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "YAML",
+			content: `
+analyze_types: true
+patterns:
+  - ^fmt\.Println$
+  - {p: ^os\.Exit$, msg: return an error instead}
+layers:
+  - {name: domain, pkg: ^example\.com/app/domain$}
+`,
+		},
+		{
+			name:    "JSON",
+			content: `{"analyze_types": true, "patterns": ["^fmt\\.Println$", {"p": "^os\\.Exit$", "msg": "return an error instead"}], "layers": [{"name": "domain", "pkg": "^example\\.com/app/domain$"}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+			config, err := LoadConfig(path)
+			require.NoError(t, err)
+			require.Len(t, config.Patterns, 2)
+			assert.Equal(t, `^fmt\.Println$`, config.Patterns[0].re.String())
+			assert.Equal(t, "return an error instead", config.Patterns[1].Msg)
+			assert.Equal(t, []Layer{{Name: "domain", Pkg: `^example\.com/app/domain$`}}, config.Layers)
+			require.NotNil(t, config.AnalyzeTypes)
+			assert.True(t, *config.AnalyzeTypes)
+			assert.Nil(t, config.Tests)
+
+			linter, err := config.NewLinter([]string{`^print$`}, OptionAnalyzeTypes(false))
+			require.NoError(t, err)
+			assert.Len(t, linter.patterns, 4)
+			assert.False(t, linter.cfg.AnalyzeTypes)
+		})
+	}
+}
+
+func TestLoadConfigWithNullValues(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+	}{
+		{name: "empty file", content: ""},
+		{name: "empty document", content: "---\n"},
+		{name: "null document", content: "~\n"},
+		{name: "property without value", content: "patterns:\nlayers: ~\n"},
+		{name: "pattern field without value", content: "patterns:\n  - {p: ^fmt\\.Println$, msg: }\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+			config, err := LoadConfig(path)
+			require.NoError(t, err)
+			assert.Empty(t, config.Layers)
+			for _, p := range config.Patterns {
+				assert.Empty(t, p.Msg)
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithUnknownField_ReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	require.NoError(t, os.WriteFile(path, []byte("pattern: [^fmt\\.Println$]\n"), 0644))
+	_, err := LoadConfig(path)
+	require.Error(t, err)
+	assert.Equal(t, path+":1:1: unknown field `pattern`", err.Error())
+}
+
+func TestLoadDirConfig(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"go.mod": "module example.com/app\n",
+		ConfigFileName: `
+analyze_types: true
+patterns:
+  - {id: exit, p: ^os\.Exit$}
+  - {id: print, p: ^fmt\.Println$}
+  - ^panic$
+layers:
+  - {name: model, pkg: ^example\.com/app/model$}
+`,
+		"legacy/" + ConfigFileName: `
+disable: [exit, model]
+`,
+		"public/" + ConfigFileName: `
+analyze_types: false
+patterns:
+  - {id: print, p: ^fmt\.Print}
+  - ^os\.Getenv$
+`,
+		"other/" + ConfigFileName: `
+root: true
+patterns:
+  - ^recover$
+`,
+	} {
+		path = filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(root, "empty"), 0755))
+
+	for _, tc := range []struct {
+		dir          string
+		patterns     []string
+		layers       int
+		analyzeTypes bool
+	}{
+		{dir: ".", patterns: []string{`^os\.Exit$`, `^fmt\.Println$`, `^panic$`}, layers: 1, analyzeTypes: true},
+		{dir: "empty", patterns: []string{`^os\.Exit$`, `^fmt\.Println$`, `^panic$`}, layers: 1, analyzeTypes: true},
+		{dir: "legacy", patterns: []string{`^fmt\.Println$`, `^panic$`}, analyzeTypes: true},
+		{dir: "public", patterns: []string{`^os\.Exit$`, `^panic$`, `^fmt\.Print`, `^os\.Getenv$`}, layers: 1},
+		{dir: "other", patterns: []string{`^recover$`}},
+	} {
+		t.Run(tc.dir, func(t *testing.T) {
+			config, err := LoadDirConfig(filepath.Join(root, tc.dir))
+			require.NoError(t, err)
+			require.NotNil(t, config)
+			patterns := make([]string, 0, len(config.Patterns))
+			for _, p := range config.Patterns {
+				patterns = append(patterns, p.re.String())
+			}
+			assert.Equal(t, tc.patterns, patterns)
+			assert.Len(t, config.Layers, tc.layers)
+			assert.Equal(t, tc.analyzeTypes, config.AnalyzeTypes != nil && *config.AnalyzeTypes)
+		})
+	}
+
+	config, err := LoadDirConfig(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, config)
+}
+
+func TestLoadConfigWithExtends(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"base/common.yml": `
+extends: [debug-prints]
+analyze_types: true
+patterns:
+  - {id: exit, p: ^os\.Exit$}
+`,
+		ConfigFileName: `
+extends: [base/common.yml, ginkgo-focus]
+disable: [debug-prints]
+patterns:
+  - {id: exit, p: ^os\.Exit$, msg: return an error}
+`,
+		"loop.yml":    `extends: [./loop.yml]`,
+		"unknown.yml": `extends: [no-such-preset]`,
+	} {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	config, err := LoadConfig(filepath.Join(dir, ConfigFileName))
+	require.NoError(t, err)
+	ids := make([]string, 0, len(config.Patterns))
+	for _, p := range config.Patterns {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []string{"ginkgo-focus", "exit"}, ids)
+	assert.Equal(t, "return an error", config.Patterns[1].Msg)
+	require.NotNil(t, config.AnalyzeTypes)
+	assert.True(t, *config.AnalyzeTypes)
+
+	_, err = LoadConfig(filepath.Join(dir, "loop.yml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extends itself")
+
+	_, err = LoadConfig(filepath.Join(dir, "unknown.yml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown preset `no-such-preset`, must be one of debug-prints, deprecated-stdlib, ginkgo-focus, test-hygiene")
+}
+
+func TestPresets(t *testing.T) {
+	for _, name := range Presets() {
+		t.Run(name, func(t *testing.T) {
+			config, err := loadPreset(name)
+			require.NoError(t, err)
+			require.NotEmpty(t, config.Patterns)
+			for _, p := range config.Patterns {
+				assert.NotEmpty(t, p.ID, "pattern %s", p.Pattern)
+			}
+		})
+	}
+}
+
+func TestDebugPrintsPresetMatchesDefaultPatterns(t *testing.T) {
+	config, err := loadPreset("debug-prints")
+	require.NoError(t, err)
+	patterns := make([]string, 0, len(config.Patterns))
+	for _, p := range config.Patterns {
+		patterns = append(patterns, p.Pattern)
+	}
+	assert.Equal(t, DefaultPatterns(), patterns, "presets/debug-prints.yml must contain the default patterns")
+}
@@ -0,0 +1,198 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// valueOps are the operators which may prefix the value of a constant
+// pattern. Longer operators come first so that `<=` isn't parsed as `<`.
+var valueOps = []token.Token{token.EQL, token.NEQ, token.LEQ, token.GEQ, token.LSS, token.GTR, token.AND}
+
+// parseValue splits the value of a constant pattern into the comparison
+// operator and the constant, which may be any constant expression that
+// doesn't refer to declared constants, for example `0o755` or `1 << 20`.
+func parseValue(value string) (token.Token, constant.Value, error) {
+	op := token.EQL
+	for _, valueOp := range valueOps {
+		if rest, ok := strings.CutPrefix(value, valueOp.String()); ok {
+			op, value = valueOp, rest
+			break
+		}
+	}
+	typeAndValue, err := types.Eval(token.NewFileSet(), nil, token.NoPos, strings.TrimSpace(value))
+	if err != nil {
+		return op, nil, err
+	}
+	if typeAndValue.Value == nil {
+		return op, nil, fmt.Errorf("not a constant")
+	}
+	return op, typeAndValue.Value, nil
+}
+
+// checkConstant matches constant expressions against the patterns for
+// constant. Only the outermost constant expression gets checked, so
+// `os.FileMode(0o777)` is reported once and not also for `0o777`.
+func (v *visitor) checkConstant(expr ast.Expr) {
+	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil {
+		return
+	}
+	if _, isParen := expr.(*ast.ParenExpr); isParen {
+		return
+	}
+	typeAndValue, ok := v.runConfig.TypesInfo.Types[expr]
+	if !ok || typeAndValue.Value == nil {
+		return
+	}
+	if parent, ok := v.parent().(ast.Expr); ok && v.runConfig.TypesInfo.Types[parent].Value != nil {
+		return
+	}
+
+	srcText := v.textFor(expr)
+	var matchTexts []matchText
+	switch expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		matchTexts = v.expandMatchText(expr, srcText)
+	default:
+		matchTexts = []matchText{{text: srcText}}
+	}
+	typeTexts := v.typeTexts(typeAndValue.Type)
+	if basic, ok := typeAndValue.Type.(*types.Basic); ok {
+		typeTexts = []matchText{{text: basic.Name()}}
+	}
+	v.runConfig.DebugLog("%s: constant %v, type %v, value %s", v.runConfig.Fset.Position(expr.Pos()), matchTexts, typeTexts, typeAndValue.Value)
+
+	for _, p := range v.linter.patterns {
+		if p.kind() != kindConstant ||
+			(p.Pattern != "" && !p.matches(matchTexts)) ||
+			(p.Type != "" && !p.matchesTexts(p.typeRe, typeTexts)) ||
+			(p.Value != "" && !compareValues(typeAndValue.Value, p.valueOp, p.value)) ||
+			(p.Const != "" && !v.matchesConst(p.Const, typeAndValue)) ||
+			!v.matchesEnclosingFunc(p) ||
+			!v.matchesContext(p) ||
+			v.permitText(expr.Pos(), srcText) {
+			continue
+		}
+		v.issues = append(v.issues, UsedIssue{
+			identifier: srcText,
+			pattern:    p.description(),
+			pos:        expr.Pos(),
+			position:   v.runConfig.Fset.Position(expr.Pos()),
+			customMsg:  p.Msg,
+		})
+	}
+}
+
+// matchesConst checks whether a constant expression has the same value as
+// the named constant. Typed constants must also have the same type, untyped
+// ones match any type.
+func (v *visitor) matchesConst(name string, typeAndValue types.TypeAndValue) bool {
+	c, ok := v.lookupObject(name).(*types.Const)
+	if !ok {
+		return false
+	}
+	if basic, ok := c.Type().(*types.Basic); !ok || basic.Info()&types.IsUntyped == 0 {
+		if !types.Identical(c.Type(), typeAndValue.Type) {
+			return false
+		}
+	}
+	return compareValues(typeAndValue.Value, token.EQL, c.Val())
+}
+
+// lookupObject finds a package-level object by its full name, for example
+// `crypto/tls.VersionTLS10`, in the current package and the packages that
+// it imports directly or indirectly. Names without a package like `error`
+// refer to the universe scope. The result is nil if the object is unknown,
+// which gets logged once per name.
+func (v *visitor) lookupObject(name string) types.Object {
+	if object, ok := v.objects[name]; ok {
+		return object
+	}
+	object, err := resolveObject(name, func(path string) (*types.Package, error) {
+		if pkg := v.findPackage(path); pkg != nil {
+			return pkg, nil
+		}
+		return nil, fmt.Errorf("package %s is not imported by %s", path, v.runConfig.PkgPath)
+	})
+	if err != nil {
+		v.runConfig.DebugLog("cannot resolve `%s`: %s", name, err)
+	}
+	v.objects[name] = object
+	return object
+}
+
+// resolveObject finds an object by its full name, using importPackage for
+// names with a package and the universe scope for the others.
+func resolveObject(name string, importPackage func(path string) (*types.Package, error)) (types.Object, error) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		if object := types.Universe.Lookup(name); object != nil {
+			return object, nil
+		}
+		return nil, fmt.Errorf("unknown name without package")
+	}
+	pkg, err := importPackage(name[:i])
+	if err != nil {
+		return nil, err
+	}
+	if object := pkg.Scope().Lookup(name[i+1:]); object != nil {
+		return object, nil
+	}
+	return nil, fmt.Errorf("package %s has no %s", pkg.Path(), name[i+1:])
+}
+
+// findPackage searches the package with the given path among the current
+// package and its imports.
+func (v *visitor) findPackage(path string) *types.Package {
+	if v.runConfig.Pkg == nil {
+		return nil
+	}
+	seen := map[*types.Package]bool{}
+	queue := []*types.Package{v.runConfig.Pkg}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if pkg.Path() == path {
+			return pkg
+		}
+		for _, imported := range pkg.Imports() {
+			if !seen[imported] {
+				seen[imported] = true
+				queue = append(queue, imported)
+			}
+		}
+	}
+	return nil
+}
+
+// compareValues applies the operator to two constants. Constants which
+// cannot be compared with the operator, for example a string and a number,
+// don't match.
+func compareValues(x constant.Value, op token.Token, y constant.Value) bool {
+	isNumeric := func(kind constant.Kind) bool {
+		return kind == constant.Int || kind == constant.Float || kind == constant.Complex
+	}
+	switch {
+	case x.Kind() == constant.Unknown || y.Kind() == constant.Unknown:
+		return false
+	case op == token.AND:
+		x, y = constant.ToInt(x), constant.ToInt(y)
+		if x.Kind() != constant.Int || y.Kind() != constant.Int {
+			return false
+		}
+		return constant.Sign(constant.BinaryOp(x, token.AND, y)) != 0
+	case isNumeric(x.Kind()) && isNumeric(y.Kind()):
+		if (x.Kind() == constant.Complex || y.Kind() == constant.Complex) && op != token.EQL && op != token.NEQ {
+			return false
+		}
+	case x.Kind() != y.Kind():
+		return false
+	case x.Kind() == constant.Bool && op != token.EQL && op != token.NEQ:
+		return false
+	}
+	return constant.Compare(x, op, y)
+}
@@ -0,0 +1,111 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// checkConstruct matches types which get instantiated without a constructor
+// against the patterns for construct. The node is the declaration of a
+// variable without value, a composite literal or a call of `new`, t is the
+// type that gets instantiated and srcText describes it in issues.
+func (v *visitor) checkConstruct(node ast.Node, t types.Type, srcText string) {
+	if t == nil {
+		return
+	}
+	if _, isPointer := t.(*types.Pointer); isPointer {
+		// A nil pointer is not a value which bypasses the constructor.
+		return
+	}
+	matchTexts := v.typeTexts(t)
+	if len(matchTexts) == 0 {
+		return
+	}
+	if pkgPath := matchTexts[0].pkg; pkgPath != "" && pkgPath == v.runConfig.PkgPath {
+		// The package which declares the type must be able to implement
+		// the constructor.
+		return
+	}
+	v.runConfig.DebugLog("%s: construct %v", v.runConfig.Fset.Position(node.Pos()), matchTexts)
+	for _, p := range v.linter.patterns {
+		if p.kind() != kindConstruct ||
+			!p.matches(matchTexts) ||
+			!v.matchesEnclosingFunc(p) ||
+			!v.matchesContext(p) ||
+			v.permitText(node.Pos(), srcText) {
+			continue
+		}
+		msg := p.Msg
+		if msg == "" && p.Via != "" {
+			msg = fmt.Sprintf("must be constructed via %s", p.Via)
+		}
+		v.issues = append(v.issues, UsedIssue{
+			identifier: srcText,
+			pattern:    p.re.String(),
+			pos:        node.Pos(),
+			position:   v.runConfig.Fset.Position(node.Pos()),
+			customMsg:  msg,
+		})
+	}
+}
+
+// checkZeroValue checks the declaration of a variable without value or a
+// call of `new`, which both create the zero value of the type expression.
+// The zero value of an array contains zero values of its element type.
+func (v *visitor) checkZeroValue(node ast.Node, typeExpr ast.Expr) {
+	if v.runConfig.TypesInfo == nil || !v.cfg.AnalyzeTypes {
+		return
+	}
+	v.checkConstruct(node, arrayElem(v.runConfig.TypesInfo.TypeOf(typeExpr)), v.textFor(typeExpr))
+}
+
+// arrayElem returns the element type of arrays, also of nested ones, and the
+// type itself for everything else.
+func arrayElem(t types.Type) types.Type {
+	for {
+		array, ok := t.(*types.Array)
+		if !ok {
+			return t
+		}
+		t = array.Elem()
+	}
+}
+
+// checkCompositeLit checks a composite literal. Its type is taken from the
+// type information because the type is omitted for elements of slices,
+// arrays and maps, like the `{}` in `[]bytes.Buffer{{}}`. If the element
+// type is a pointer, the omitted type includes the `&`, as in
+// `[]*bytes.Buffer{{}}`. Array literals with fewer elements than the length
+// of the array, like `[2]bytes.Buffer{}`, contain zero values of the element
+// type. Elements which are listed get checked on their own.
+func (v *visitor) checkCompositeLit(lit *ast.CompositeLit) {
+	if v.runConfig.TypesInfo == nil || !v.cfg.AnalyzeTypes {
+		return
+	}
+	t := indirect(v.runConfig.TypesInfo.TypeOf(lit))
+	if array, ok := t.(*types.Array); ok {
+		if int64(len(lit.Elts)) >= array.Len() {
+			return
+		}
+		t = arrayElem(array)
+	}
+	srcText := v.typeString(t)
+	if lit.Type != nil {
+		srcText = v.textFor(lit.Type)
+	}
+	v.checkConstruct(lit, t, srcText)
+}
+
+// newArgument returns the type argument if the call is a call of the
+// builtin `new`.
+func (v *visitor) newArgument(call *ast.CallExpr) ast.Expr {
+	ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || len(call.Args) != 1 || v.runConfig.TypesInfo == nil {
+		return nil
+	}
+	if builtin, ok := v.runConfig.TypesInfo.Uses[ident].(*types.Builtin); !ok || builtin.Name() != "new" {
+		return nil
+	}
+	return call.Args[0]
+}
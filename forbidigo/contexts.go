@@ -0,0 +1,71 @@
+package forbidigo
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Supported values for pattern.Context and pattern.NotContext.
+const (
+	contextGo        = "go"
+	contextDefer     = "defer"
+	contextLoop      = "loop"
+	contextInit      = "init"
+	contextGlobalVar = "global_var"
+)
+
+// contexts lists all supported values for pattern.Context and pattern.NotContext.
+var contexts = []string{contextGo, contextDefer, contextLoop, contextInit, contextGlobalVar}
+
+// inContext checks whether the current node is inside a statement of the
+// given kind:
+//
+//   - go: a `go` statement, including function literals started by it.
+//   - defer: a `defer` statement, including deferred function literals.
+//   - loop: the body of a `for` or `range` loop or the condition and post
+//     statement of a `for` loop, i.e. code which runs once per iteration.
+//   - init: an `init` function.
+//   - global_var: the initialization of a package-level variable.
+func (v *visitor) inContext(context string) bool {
+	for i := len(v.stack) - 2; i >= 0; i-- {
+		child := v.stack[i+1]
+		switch node := v.stack[i].(type) {
+		case *ast.GoStmt:
+			if context == contextGo {
+				return true
+			}
+		case *ast.DeferStmt:
+			if context == contextDefer {
+				return true
+			}
+		case *ast.ForStmt:
+			if context == contextLoop && (child == node.Body || child == node.Cond || child == node.Post) {
+				return true
+			}
+		case *ast.RangeStmt:
+			if context == contextLoop && child == node.Body {
+				return true
+			}
+		case *ast.FuncDecl:
+			if context == contextInit && node.Recv == nil && node.Name.Name == "init" {
+				return true
+			}
+		case *ast.ValueSpec:
+			if context == contextGlobalVar && i >= 2 {
+				genDecl, isGenDecl := v.stack[i-1].(*ast.GenDecl)
+				_, isFile := v.stack[i-2].(*ast.File)
+				if isGenDecl && genDecl.Tok == token.VAR && isFile {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// matchesContext checks the `context` and `not_context` restrictions of a
+// pattern.
+func (v *visitor) matchesContext(p *pattern) bool {
+	return (p.Context == "" || v.inContext(p.Context)) &&
+		(p.NotContext == "" || !v.inContext(p.NotContext))
+}
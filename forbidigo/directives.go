@@ -0,0 +1,116 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// directive matches comments which are compiler directives, using the same
+// rules as go/ast: `//line`, `//extern` and `//export` followed by a space,
+// and `//<tool>:<directive>` like `//go:linkname`.
+var directive = regexp.MustCompile(`^//(line |extern |export |[a-z0-9]+:[a-z0-9])`)
+
+// notDirectives are the prefixes of comments which look like directives for
+// some tool, but which are meant for linters, for example `//nolint:errcheck`
+// or `//permit://go:linkname`.
+var notDirectives = []string{"permit:", "nolint:"}
+
+// cgoGenerated is the comment at the start of the Go files generated by cgo.
+const cgoGenerated = "// Code generated by cmd/cgo; DO NOT EDIT."
+
+// checkDirectives matches the compiler directives in a file against the
+// patterns for directives and the commands in `//go:generate` directives
+// against the patterns for generate.
+func (v *visitor) checkDirectives(file *ast.File) {
+	// commands maps the names defined by `//go:generate -command` to the
+	// command that they stand for.
+	commands := map[string]string{}
+	generatedByCgo := len(file.Comments) > 0 && file.Comments[0].List[0].Text == cgoGenerated
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if !directive.MatchString(comment.Text) {
+				continue
+			}
+			text := strings.TrimPrefix(comment.Text, "//")
+			if slices.ContainsFunc(notDirectives, func(prefix string) bool { return strings.HasPrefix(text, prefix) }) {
+				continue
+			}
+			name, args, _ := strings.Cut(text, " ")
+			// cgo generates `//line` directives which map its output back
+			// to the source code and `//go:cgo_*` directives, which are
+			// only allowed in generated code.
+			if (generatedByCgo && name == "line") || strings.HasPrefix(name, "go:cgo_") {
+				continue
+			}
+			v.runConfig.DebugLog("%s: directive %q", v.runConfig.Fset.Position(comment.Pos()), text)
+			if name == "go:generate" {
+				v.checkGenerate(comment, strings.TrimSpace(args), commands)
+			}
+			for _, p := range v.linter.patterns {
+				if p.kind() != kindDirective || !p.re.MatchString(text) || v.permitDirective(comment, name) {
+					continue
+				}
+				v.issues = append(v.issues, UsedIssue{
+					identifier: "//" + name,
+					pattern:    p.re.String(),
+					pos:        comment.Pos(),
+					position:   v.runConfig.Fset.Position(comment.Pos()),
+					customMsg:  p.Msg,
+				})
+			}
+		}
+	}
+}
+
+// checkGenerate matches the command in a `//go:generate` directive against
+// the patterns for generate. Commands that use a name defined earlier in the
+// file with `//go:generate -command <name> <command>` get matched both as
+// written and with the name replaced by the command.
+func (v *visitor) checkGenerate(comment *ast.Comment, command string, commands map[string]string) {
+	matchTexts := []string{command}
+	words := strings.Fields(command)
+	switch {
+	case len(words) >= 2 && words[0] == "-command":
+		commands[words[1]] = strings.Join(words[2:], " ")
+	case len(words) >= 1 && commands[words[0]] != "":
+		matchTexts = append(matchTexts, strings.Join(append([]string{commands[words[0]]}, words[1:]...), " "))
+	}
+	v.runConfig.DebugLog("%s: generate %q", v.runConfig.Fset.Position(comment.Pos()), matchTexts)
+	for _, p := range v.linter.patterns {
+		if p.kind() != kindGenerate || !slices.ContainsFunc(matchTexts, p.re.MatchString) || v.permitDirective(comment, "go:generate") {
+			continue
+		}
+		v.issues = append(v.issues, UsedIssue{
+			identifier: command,
+			pattern:    p.re.String(),
+			pos:        comment.Pos(),
+			position:   v.runConfig.Fset.Position(comment.Pos()),
+			customMsg:  p.Msg,
+		})
+	}
+}
+
+// permitDirective checks for a `permit` directive for a compiler directive.
+// Because a compiler directive extends to the end of the line, the `permit`
+// directive has to be on the line before it, for example:
+//
+//	//permit://go:linkname
+//	//go:linkname nanotime runtime.nanotime
+func (v *visitor) permitDirective(comment *ast.Comment, name string) bool {
+	if v.cfg.IgnorePermitDirectives {
+		return false
+	}
+	line := v.runConfig.Fset.Position(comment.Pos()).Line
+	permit := regexp.MustCompile(fmt.Sprintf(`^//\s?permit:(//)?%s\b`, regexp.QuoteMeta(name)))
+	for _, group := range v.comments {
+		for _, c := range group.List {
+			if v.runConfig.Fset.Position(c.Pos()).Line == line-1 && permit.MatchString(c.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
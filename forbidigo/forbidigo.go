@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"go/ast"
 	"go/printer"
+	"go/scanner"
 	"go/token"
 	"go/types"
 	"log"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -53,6 +55,10 @@ func toString(i UsedIssue) string {
 type Linter struct {
 	cfg      config
 	patterns []*pattern
+
+	// hasKind records which kinds of patterns exist, so that checks for
+	// kinds without patterns can be skipped.
+	hasKind map[string]bool
 }
 
 func DefaultPatterns() []string {
@@ -65,18 +71,26 @@ type config struct {
 	ExcludeGodocExamples   bool `options:",true"`
 	IgnorePermitDirectives bool // don't check for `permit` directives(for example, in favor of `nolint`)
 	AnalyzeTypes           bool // enable to match canonical names for types and interfaces using type info
+	AnalyzeReflection      bool // enable to match methods and fields that get looked up by name via reflect, requires AnalyzeTypes
 }
 
 func NewLinter(patterns []string, options ...Option) (*Linter, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns()
+	}
+	return newLinter(nil, patterns, options...)
+}
+
+// newLinter creates a linter for patterns which were parsed already and
+// patterns which still need to be parsed.
+func newLinter(parsed []*pattern, patterns []string, options ...Option) (*Linter, error) {
 	cfg, err := newConfig(options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process options: %w", err)
 	}
 
-	if len(patterns) == 0 {
-		patterns = DefaultPatterns()
-	}
-	compiledPatterns := make([]*pattern, 0, len(patterns))
+	compiledPatterns := make([]*pattern, 0, len(parsed)+len(patterns))
+	compiledPatterns = append(compiledPatterns, parsed...)
 	for _, ptrn := range patterns {
 		p, err := parse(ptrn)
 		if err != nil {
@@ -84,9 +98,17 @@ func NewLinter(patterns []string, options ...Option) (*Linter, error) {
 		}
 		compiledPatterns = append(compiledPatterns, p)
 	}
+	if err := validateLayers(compiledPatterns); err != nil {
+		return nil, err
+	}
+	hasKind := map[string]bool{}
+	for _, p := range compiledPatterns {
+		hasKind[p.kind()] = true
+	}
 	return &Linter{
 		cfg:      cfg,
 		patterns: compiledPatterns,
+		hasKind:  hasKind,
 	}, nil
 }
 
@@ -97,6 +119,17 @@ type visitor struct {
 	linter   *Linter
 	comments []*ast.CommentGroup
 
+	// root is the node that gets visited, usually an *ast.File.
+	root ast.Node
+	// names gets populated on demand by scopeNames.
+	names *scopeNames
+	// stack contains all nodes from the root to the one currently visited.
+	stack []ast.Node
+	// objects caches the objects which were looked up by their full name.
+	objects map[string]types.Object
+	// reflectVars gets populated on demand by reflectVarInits.
+	reflectVars map[types.Object]ast.Expr
+
 	runConfig RunConfig
 	issues    []Issue
 }
@@ -118,6 +151,15 @@ type RunConfig struct {
 	// Nil disables that step, i.e. patterns match the literal source code.
 	TypesInfo *types.Info
 
+	// PkgPath is the full path of the package that gets analyzed. It is
+	// used for matching the names of enclosing functions. May be empty.
+	PkgPath string
+
+	// Pkg is the package that gets analyzed. It is used for looking up
+	// the constants and interfaces that patterns refer to. If it is nil,
+	// it gets determined from TypesInfo.
+	Pkg *types.Package
+
 	// DebugLog is used to print debug messages. May be nil.
 	DebugLog func(format string, args ...interface{})
 }
@@ -126,6 +168,17 @@ func (l *Linter) RunWithConfig(config RunConfig, nodes ...ast.Node) ([]Issue, er
 	if config.DebugLog == nil {
 		config.DebugLog = func(format string, args ...interface{}) {}
 	}
+	if config.Pkg == nil && config.TypesInfo != nil {
+		for _, object := range config.TypesInfo.Defs {
+			if object != nil && object.Pkg() != nil {
+				config.Pkg = object.Pkg()
+				break
+			}
+		}
+	}
+	// objects is shared by all files because they belong to the same
+	// package.
+	objects := map[string]types.Object{}
 	var issues []Issue
 	for _, node := range nodes {
 		var comments []*ast.CommentGroup
@@ -162,7 +215,7 @@ func (l *Linter) RunWithConfig(config RunConfig, nodes ...ast.Node) ([]Issue, er
 				isWholeFileExample = numExamples == 1 && numTestsAndBenchmarks == 0
 			}
 		}
-		if isWholeFileExample {
+		if isWholeFileExample || isCgoTypesFile(node) {
 			continue
 		}
 		visitor := visitor{
@@ -171,14 +224,38 @@ func (l *Linter) RunWithConfig(config RunConfig, nodes ...ast.Node) ([]Issue, er
 			linter:     l,
 			runConfig:  config,
 			comments:   comments,
+			root:       node,
+			objects:    objects,
 		}
 		ast.Walk(&visitor, node)
+		if file, ok := node.(*ast.File); ok {
+			visitor.checkDirectives(file)
+		}
 		issues = append(issues, visitor.issues...)
 	}
 	return issues, nil
 }
 
 func (v *visitor) Visit(node ast.Node) ast.Visitor {
+	// Keep track of the nodes which enclose the current one. Visit(nil)
+	// gets called for each node for which a non-nil visitor was returned
+	// once all of its children have been visited.
+	if node == nil {
+		v.stack = v.stack[:len(v.stack)-1]
+		return nil
+	}
+	v.stack = append(v.stack, node)
+	w := v.visit(node)
+	if w == nil {
+		v.stack = v.stack[:len(v.stack)-1]
+	}
+	return w
+}
+
+func (v *visitor) visit(node ast.Node) ast.Visitor {
+	if expr, ok := node.(ast.Expr); ok && v.linter.hasKind[kindConstant] {
+		v.checkConstant(expr)
+	}
 	switch node := node.(type) {
 	case *ast.FuncDecl:
 		// don't descend into godoc examples if we are ignoring them
@@ -193,6 +270,9 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 		return nil
 	// Ignore constant and type names
 	case *ast.ValueSpec:
+		if node.Values == nil && node.Type != nil && v.linter.hasKind[kindConstruct] {
+			v.checkZeroValue(node, node.Type)
+		}
 		// Look at only type and values for const and variable specs, and not names
 		if node.Type != nil {
 			ast.Walk(v, node.Type)
@@ -219,7 +299,53 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 		if node.Type != nil {
 			ast.Walk(v, node.Type)
 		}
+		if node.Tag != nil && v.linter.hasKind[kindTag] {
+			v.checkTag(node.Tag)
+		}
+		return nil
+	case *ast.BasicLit:
+		if v.linter.hasKind[kindLiteral] {
+			v.checkLiteral(node)
+		}
 		return nil
+	case *ast.CompositeLit:
+		if v.linter.hasKind[kindConstruct] {
+			v.checkCompositeLit(node)
+		}
+		return v
+	case *ast.CallExpr:
+		if v.linter.hasKind[kindConstruct] {
+			if typeExpr := v.newArgument(node); typeExpr != nil {
+				v.checkZeroValue(node, typeExpr)
+			}
+		}
+		if v.linter.hasKind[kindSignature] {
+			v.checkSignature(node)
+		}
+		if v.cfg.AnalyzeReflection {
+			v.checkReflection(node)
+		}
+		return v
+	case *ast.BinaryExpr:
+		if v.linter.hasKind[kindCompare] {
+			v.checkComparison(node)
+		}
+		return v
+	case *ast.SwitchStmt:
+		if v.linter.hasKind[kindCompare] {
+			v.checkSwitchComparisons(node)
+		}
+		return v
+	case *ast.TypeAssertExpr:
+		if v.linter.hasKind[kindAssert] {
+			v.checkTypeAssertion(node)
+		}
+		return v
+	case *ast.TypeSwitchStmt:
+		if v.linter.hasKind[kindAssert] {
+			v.checkTypeSwitch(node)
+		}
+		return v
 	// The following two are handled below.
 	case *ast.SelectorExpr:
 	case *ast.Ident:
@@ -232,32 +358,189 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 	// use that. It's used for matching unless usage of type information
 	// is enabled.
 	srcText := v.textFor(node)
-	matchTexts, pkgText := v.expandMatchText(node, srcText)
-	v.runConfig.DebugLog("%s: match %v, package %q", v.runConfig.Fset.Position(node.Pos()), matchTexts, pkgText)
+	matchTexts := v.expandMatchText(node, srcText)
+	usage := v.usage(node)
+	access := v.access()
+	v.runConfig.DebugLog("%s: match %v, usage %q, access %q", v.runConfig.Fset.Position(node.Pos()), matchTexts, usage, access)
+	if v.linter.hasKind[kindLayer] {
+		v.checkLayers(node, srcText)
+	}
+	v.reportIdentifier(node, srcText, matchTexts, usage, access, v.callDiscarded())
+
+	// descend into the left-side of selectors
+	if selector, isSelector := node.(*ast.SelectorExpr); isSelector {
+		if _, leftSideIsIdentifier := selector.X.(*ast.Ident); !leftSideIsIdentifier {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// reportIdentifier matches the texts for an identifier against the patterns
+// for identifiers. The node is what gets reported, with srcText as the text.
+func (v *visitor) reportIdentifier(node ast.Node, srcText string, matchTexts []matchText, usage, access string, discarded bool) {
 	for _, p := range v.linter.patterns {
-		if p.matches(matchTexts) &&
-			(p.Package == "" || p.pkgRe.MatchString(pkgText)) &&
-			!v.permit(node) {
+		if p.kind() == kindIdentifier &&
+			p.forbids(matchTexts) &&
+			(p.Usage == "" || p.Usage == usage) &&
+			(p.Access == "" || p.Access == access) &&
+			(!p.Discarded || discarded) &&
+			(p.Implements == "" || v.implements(node, p.Implements)) &&
+			v.matchesEnclosingFunc(p) &&
+			v.matchesContext(p) &&
+			!v.permitText(node.Pos(), srcText) {
 			v.issues = append(v.issues, UsedIssue{
 				identifier: srcText, // Always report the expression as it appears in the source code.
-				pattern:    p.re.String(),
+				pattern:    p.description(),
 				pos:        node.Pos(),
 				position:   v.runConfig.Fset.Position(node.Pos()),
 				customMsg:  p.Msg,
 			})
 		}
 	}
+}
 
-	// descend into the left-side of selectors
-	if selector, isSelector := node.(*ast.SelectorExpr); isSelector {
-		if _, leftSideIsIdentifier := selector.X.(*ast.Ident); !leftSideIsIdentifier {
-			return v
+// parent returns the node which encloses the current one, skipping over
+// parentheses. The result is nil for the root node.
+func (v *visitor) parent() ast.Node {
+	for i := len(v.stack) - 2; i >= 0; i-- {
+		if _, isParen := v.stack[i].(*ast.ParenExpr); !isParen {
+			return v.stack[i]
 		}
 	}
-
 	return nil
 }
 
+// usage determines whether the current expression gets called (usageCall),
+// is a method expression like `(*T).Method` (usageExpression) or gets used in
+// some other way, for example as method value (usageValue). Method
+// expressions and conversions can only be detected with type information.
+func (v *visitor) usage(node ast.Node) string {
+	if selector, ok := node.(*ast.SelectorExpr); ok && v.cfg.AnalyzeTypes && v.runConfig.TypesInfo != nil {
+		if selection, ok := v.runConfig.TypesInfo.Selections[selector]; ok && selection.Kind() == types.MethodExpr {
+			return usageExpression
+		}
+	}
+	call, ok := v.parent().(*ast.CallExpr)
+	if !ok || ast.Unparen(call.Fun) != node {
+		return usageValue
+	}
+	if v.cfg.AnalyzeTypes && v.runConfig.TypesInfo != nil {
+		if typeAndValue, ok := v.runConfig.TypesInfo.Types[call.Fun]; ok && typeAndValue.IsType() {
+			// A conversion.
+			return usageValue
+		}
+	}
+	return usageCall
+}
+
+// callDiscarded checks whether the current expression gets called and the
+// result of that call gets ignored, i.e. the call is an expression statement,
+// gets assigned to `_` or is started by `go` or `defer`.
+func (v *visitor) callDiscarded() bool {
+	node := v.stack[len(v.stack)-1]
+	for i := len(v.stack) - 2; i >= 0; i-- {
+		switch parent := v.stack[i].(type) {
+		case *ast.ParenExpr:
+			continue
+		case *ast.CallExpr:
+			return ast.Unparen(parent.Fun) == node && v.resultDiscarded(i)
+		}
+		return false
+	}
+	return false
+}
+
+// resultDiscarded checks whether the result of the call at the given index in
+// the stack gets ignored.
+func (v *visitor) resultDiscarded(callIndex int) bool {
+	call := v.stack[callIndex]
+	for i := callIndex - 1; i >= 0; i-- {
+		switch parent := v.stack[i].(type) {
+		case *ast.ParenExpr:
+			call = parent
+			continue
+		case *ast.ExprStmt, *ast.GoStmt, *ast.DeferStmt:
+			return true
+		case *ast.AssignStmt:
+			return assignedToBlank(call, parent.Lhs, parent.Rhs)
+		case *ast.ValueSpec:
+			names := make([]ast.Expr, 0, len(parent.Names))
+			for _, name := range parent.Names {
+				names = append(names, name)
+			}
+			return assignedToBlank(call, names, parent.Values)
+		}
+		return false
+	}
+	return false
+}
+
+// assignedToBlank checks whether all results of a call on the right-hand
+// side of an assignment or variable declaration get assigned to `_`.
+func assignedToBlank(call ast.Node, lhs, rhs []ast.Expr) bool {
+	isBlank := func(expr ast.Expr) bool {
+		ident, ok := expr.(*ast.Ident)
+		return ok && ident.Name == "_"
+	}
+	if len(rhs) == 1 {
+		// All results go to the left-hand side.
+		return !slices.ContainsFunc(lhs, func(expr ast.Expr) bool { return !isBlank(expr) })
+	}
+	for i, expr := range rhs {
+		if expr == call && i < len(lhs) {
+			return isBlank(lhs[i])
+		}
+	}
+	return false
+}
+
+// access determines whether the current expression gets assigned to or
+// incremented/decremented (accessWrite), has its address taken (accessAddress)
+// or gets read (accessRead). Writing to or taking the address of a field or
+// element counts as writing to or taking the address of the expression that
+// contains it, so `pkg.Config.Timeout = 1` writes `pkg.Config`.
+func (v *visitor) access() string {
+	current := v.stack[len(v.stack)-1]
+	for i := len(v.stack) - 2; i >= 0; i-- {
+		switch parent := v.stack[i].(type) {
+		case *ast.ParenExpr:
+		case *ast.SelectorExpr:
+			if parent.X != current {
+				return accessRead
+			}
+		case *ast.IndexExpr:
+			if parent.X != current {
+				return accessRead
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range parent.Lhs {
+				if lhs == current {
+					return accessWrite
+				}
+			}
+			return accessRead
+		case *ast.IncDecStmt:
+			return accessWrite
+		case *ast.RangeStmt:
+			if parent.Tok == token.ASSIGN && (parent.Key == current || parent.Value == current) {
+				return accessWrite
+			}
+			return accessRead
+		case *ast.UnaryExpr:
+			if parent.Op == token.AND {
+				return accessAddress
+			}
+			return accessRead
+		default:
+			return accessRead
+		}
+		current = v.stack[i]
+	}
+	return accessRead
+}
+
 // textFor returns the expression as it appears in the source code (for
 // example, <importname>.<function name>).
 func (v *visitor) textFor(node ast.Node) string {
@@ -265,49 +548,148 @@ func (v *visitor) textFor(node ast.Node) string {
 	if err := printer.Fprint(buf, v.runConfig.Fset, node); err != nil {
 		log.Fatalf("ERROR: unable to print node at %s: %s", v.runConfig.Fset.Position(node.Pos()), err)
 	}
+	// Undo the renaming of C.<name> by cgo.
+	return undoCgoRenaming(buf.String())
+}
+
+// cgoPrefixes are the prefixes of the identifiers that cgo generates for the
+// symbols that get referenced as C.<name> in the source code, for example
+// _Cfunc_free for C.free.
+var cgoPrefixes = []string{"_Cfunc_", "_Ctype_", "_Cvar_", "_Cmacro_", "_Cconst_", "_Cfpvar_fp_"}
+
+// cgoName returns the name of the C symbol for an identifier generated by cgo.
+func cgoName(ident string) (string, bool) {
+	if !strings.HasPrefix(ident, "_C") {
+		return "", false
+	}
+	for _, prefix := range cgoPrefixes {
+		if name, ok := strings.CutPrefix(ident, prefix); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// undoCgoRenaming replaces the identifiers generated by cgo in source code
+// with C.<name>. Other tokens, for example string literals, are left alone.
+func undoCgoRenaming(src string) string {
+	if !strings.Contains(src, "_C") {
+		return src
+	}
+	var s scanner.Scanner
+	file := token.NewFileSet().AddFile("", -1, len(src))
+	s.Init(file, []byte(src), nil, 0)
+	var buf strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if name, ok := cgoName(lit); ok && tok == token.IDENT {
+			offset := file.Offset(pos)
+			buf.WriteString(src[last:offset])
+			buf.WriteString("C." + name)
+			last = offset + len(lit)
+		}
+	}
+	buf.WriteString(src[last:])
 	return buf.String()
 }
 
+// isCgoTypesFile detects the _cgo_gotypes.go file that cgo generates with
+// declarations for all referenced C symbols. Those declarations don't appear
+// in the source code, so matching them would only lead to confusing issues.
+func isCgoTypesFile(node ast.Node) bool {
+	file, ok := node.(*ast.File)
+	if !ok {
+		return false
+	}
+	for _, imp := range file.Imports {
+		if imp.Name != nil && imp.Name.Name == "_cgopackage" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchText is one candidate text that patterns get matched against,
+// together with the full path of the package that it belongs to.
+type matchText struct {
+	text string
+	pkg  string
+
+	// alias is set when the text uses the name of a type alias instead of
+	// the name of the type that the alias refers to.
+	alias bool
+}
+
+func (m matchText) String() string {
+	if m.alias {
+		return fmt.Sprintf("%q (alias, package %q)", m.text, m.pkg)
+	}
+	return fmt.Sprintf("%q (package %q)", m.text, m.pkg)
+}
+
 // expandMatchText expands the selector in a selector expression to the full package
 // name and (for variables) the type:
 //
 // - example.com/some/pkg.Function
 // - example.com/some/pkg.CustomType.Method
 //
-// It updates the text to match against and fills the package string if possible,
-// otherwise it just returns.
-func (v *visitor) expandMatchText(node ast.Node, srcText string) (matchTexts []string, pkgText string) {
+// When a type alias is involved, both the text using the alias and the text
+// using the type that it refers to are returned.
+//
+// It returns the literal source code if the expression cannot be expanded.
+func (v *visitor) expandMatchText(node ast.Node, srcText string) (matchTexts []matchText) {
 	// The text to match against is the literal source code if we cannot
 	// come up with something different.
-	matchTexts = []string{srcText}
+	matchTexts = []matchText{{text: srcText}}
 
 	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil {
-		return matchTexts, pkgText
+		return matchTexts
 	}
 
 	location := v.runConfig.Fset.Position(node.Pos())
 
 	switch node := node.(type) {
 	case *ast.Ident:
-		if object, ok := v.runConfig.TypesInfo.Uses[node]; !ok {
+		if _, ok := cgoName(node.Name); ok {
+			// Symbols from the C pseudo-package get declared by cgo
+			// in the current package, but belong to "C".
+			matchTexts = []matchText{{text: srcText, pkg: "C"}}
+			v.runConfig.DebugLog("%s: identifier: %q -> %v from cgo", location, node.Name, matchTexts)
+		} else if object, ok := v.runConfig.TypesInfo.Uses[node]; !ok {
 			// No information about the identifier. Should
 			// not happen, but perhaps there were compile
 			// errors?
 			v.runConfig.DebugLog("%s: unknown identifier %q", location, srcText)
 		} else if pkg := object.Pkg(); pkg != nil {
-			pkgText = pkg.Path()
 			// if this is a method, don't include the package name
 			isMethod := false
 			if signature, ok := object.Type().(*types.Signature); ok && signature.Recv() != nil {
 				isMethod = true
 			}
-			v.runConfig.DebugLog("%s: identifier: %q -> %q in package %q", location, srcText, matchTexts, pkgText)
 			// match either with or without package name
 			if !isMethod {
-				matchTexts = []string{pkg.Name() + "." + srcText, srcText}
+				isAlias := isAliasTypeName(object)
+				name := srcText
+				if typeName, ok := object.(*types.TypeName); ok {
+					name = v.localName(typeName)
+				}
+				matchTexts = []matchText{
+					{text: pkg.Name() + "." + name, pkg: pkg.Path(), alias: isAlias},
+					{text: srcText, pkg: pkg.Path(), alias: isAlias},
+				}
+				if isAlias {
+					matchTexts = append(matchTexts, v.resolvedTypeTexts(object.Type())...)
+				}
+			} else {
+				matchTexts = []matchText{{text: srcText, pkg: pkg.Path()}}
 			}
+			v.runConfig.DebugLog("%s: identifier: %q -> %v", location, srcText, matchTexts)
 		} else {
-			v.runConfig.DebugLog("%s: identifier: %q -> %q without package", location, srcText, matchTexts)
+			v.runConfig.DebugLog("%s: identifier: %q -> %v without package", location, srcText, matchTexts)
 		}
 	case *ast.SelectorExpr:
 		selector := node.X
@@ -317,14 +699,13 @@ func (v *visitor) expandMatchText(node ast.Node, srcText string) (matchTexts []s
 		// type. We don't care about the value.
 		selectorText := v.textFor(node)
 		if typeAndValue, ok := v.runConfig.TypesInfo.Types[selector]; ok {
-			if typeName, pkgPath, ok := typeNameWithPackage(typeAndValue.Type); ok {
-				v.runConfig.DebugLog("%s: selector %q with supported type %q: %q -> %q, package %q", location, selectorText, typeAndValue.Type.String(), srcText, matchTexts, pkgPath)
-				matchTexts = []string{typeName + "." + field}
-				pkgText = pkgPath
+			if texts, ok := v.selectorTexts(typeAndValue.Type, field); ok {
+				matchTexts = texts
+				v.runConfig.DebugLog("%s: selector %q with supported type %q: %q -> %v", location, selectorText, typeAndValue.Type.String(), srcText, matchTexts)
 			} else {
 				// handle cases such as anonymous structs
 				v.runConfig.DebugLog("%s: selector %q with unknown type %T", location, selectorText, typeAndValue.Type)
-				matchTexts = []string{}
+				matchTexts = []matchText{}
 			}
 		}
 		// Some expressions need special treatment.
@@ -333,18 +714,21 @@ func (v *visitor) expandMatchText(node ast.Node, srcText string) (matchTexts []s
 			if object, hasUses := v.runConfig.TypesInfo.Uses[selector]; hasUses {
 				switch object := object.(type) {
 				case *types.PkgName:
-					pkgText = object.Imported().Path()
-					matchTexts = []string{object.Imported().Name() + "." + field}
-					v.runConfig.DebugLog("%s: selector %q is package: %q -> %q, package %q", location, selectorText, srcText, matchTexts, pkgText)
+					pkgText := object.Imported().Path()
+					isAlias := isAliasTypeName(v.runConfig.TypesInfo.Uses[node.Sel])
+					matchTexts = []matchText{{text: object.Imported().Name() + "." + field, pkg: pkgText, alias: isAlias}}
+					if isAlias {
+						matchTexts = append(matchTexts, v.resolvedTypeTexts(v.runConfig.TypesInfo.Uses[node.Sel].Type())...)
+					}
+					v.runConfig.DebugLog("%s: selector %q is package: %q -> %v", location, selectorText, srcText, matchTexts)
 				case *types.Var:
-					if typeName, packageName, ok := typeNameWithPackage(object.Type()); ok {
-						matchTexts = []string{typeName + "." + field}
-						pkgText = packageName
-						v.runConfig.DebugLog("%s: selector %q is variable of type %q: %q -> %q, package %q", location, selectorText, object.Type().String(), srcText, matchTexts, pkgText)
+					if texts, ok := v.selectorTexts(object.Type(), field); ok {
+						matchTexts = texts
+						v.runConfig.DebugLog("%s: selector %q is variable of type %q: %q -> %v", location, selectorText, object.Type().String(), srcText, matchTexts)
 					} else {
 						// handle cases such as anonymous structs
 						v.runConfig.DebugLog("%s: selector %q is variable with unsupported type %T", location, selectorText, object.Type())
-						matchTexts = []string{}
+						matchTexts = []matchText{}
 					}
 				default:
 					// Something else?
@@ -362,39 +746,116 @@ func (v *visitor) expandMatchText(node ast.Node, srcText string) (matchTexts []s
 	default:
 		v.runConfig.DebugLog("%s: unsupported type %T", location, node)
 	}
-	return matchTexts, pkgText
+	return matchTexts
+}
+
+// selectorTexts returns the texts for selecting a field or method from a
+// value of the given type. If the type is an alias, the text for the alias is
+// returned in addition to the text for the type that it refers to.
+func (v *visitor) selectorTexts(t types.Type, field string) ([]matchText, bool) {
+	texts := v.typeTexts(t)
+	for i := range texts {
+		texts[i].text += "." + field
+	}
+	return texts, len(texts) > 0
+}
+
+// typeTexts returns the texts for a type, or nil if the type is not supported.
+// If the type is an alias, the text for the alias is returned in addition to
+// the text for the type that it refers to.
+func (v *visitor) typeTexts(t types.Type) []matchText {
+	typeName, pkgPath, ok := v.typeNameWithPackage(t)
+	if !ok {
+		return nil
+	}
+	texts := []matchText{{text: typeName, pkg: pkgPath}}
+	if aliasName, aliasPkgPath, ok := v.aliasNameWithPackage(t); ok {
+		texts = append(texts, matchText{text: aliasName, pkg: aliasPkgPath, alias: true})
+	}
+	return texts
+}
+
+// resolvedTypeTexts returns the text for the type that a type alias refers
+// to, if that type has a name.
+func (v *visitor) resolvedTypeTexts(t types.Type) []matchText {
+	typeName, pkgPath, ok := v.typeNameWithPackage(t)
+	if !ok {
+		return nil
+	}
+	return []matchText{{text: typeName, pkg: pkgPath}}
+}
+
+// isAliasTypeName checks whether the object is the name of a type alias.
+func isAliasTypeName(object types.Object) bool {
+	typeName, ok := object.(*types.TypeName)
+	return ok && typeName.IsAlias()
 }
 
 // typeNameWithPackage tries to determine `<package name>.<type name>` and the full
 // package path. This only needs to work for types of a selector in a selector
-// expression.
-func typeNameWithPackage(t types.Type) (typeName, packagePath string, ok bool) {
+// expression. Type aliases get resolved to the type that they refer to.
+func (v *visitor) typeNameWithPackage(t types.Type) (typeName, packagePath string, ok bool) {
 	if ptr, ok := t.(*types.Pointer); ok {
 		t = ptr.Elem()
 	}
 
 	switch t := t.(type) {
 	case *types.Alias:
-		return typeNameWithPackage(t.Rhs())
+		return v.typeNameWithPackage(t.Rhs())
 	case *types.Named:
-		obj := t.Obj()
-		pkg := obj.Pkg()
-		// we either lack a package or the package is the "universe" (i.e. builtin)
-		if pkg == nil {
-			return obj.Name(), "", true
-		}
-		return pkg.Name() + "." + obj.Name(), pkg.Path(), true
+		return v.objectNameWithPackage(t.Obj())
+	case *types.Struct, *types.Interface:
+		// Anonymous types are represented by their structure, for example
+		// `struct{Println string}`. They don't belong to any package.
+		return types.TypeString(t, func(pkg *types.Package) string { return pkg.Name() }), "", true
 	default:
 		return "", "", false
 	}
 }
 
+// aliasNameWithPackage is like typeNameWithPackage, except that it returns
+// the name of the type alias itself and fails for other types.
+func (v *visitor) aliasNameWithPackage(t types.Type) (typeName, packagePath string, ok bool) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	if alias, ok := t.(*types.Alias); ok {
+		return v.objectNameWithPackage(alias.Obj())
+	}
+	return "", "", false
+}
+
+// objectNameWithPackage determines `<package name>.<type name>` for a type
+// name. Types declared inside a function get the name of that function
+// inserted, as in `<package name>.<function name>.<type name>`.
+func (v *visitor) objectNameWithPackage(obj *types.TypeName) (typeName, packagePath string, ok bool) {
+	pkg := obj.Pkg()
+	// we either lack a package or the package is the "universe" (i.e. builtin)
+	if pkg == nil {
+		return obj.Name(), "", true
+	}
+	return pkg.Name() + "." + v.localName(obj), pkg.Path(), true
+}
+
 func (v *visitor) permit(node ast.Node) bool {
+	return v.permitText(node.Pos(), v.textFor(node))
+}
+
+// permitText checks for a `permit` directive for the text on the same line
+// as the position.
+func (v *visitor) permitText(pos token.Pos, text string) bool {
 	if v.cfg.IgnorePermitDirectives {
 		return false
 	}
-	nodePos := v.runConfig.Fset.Position(node.Pos())
-	nolint := regexp.MustCompile(fmt.Sprintf(`^//\s?permit:%s\b`, regexp.QuoteMeta(v.textFor(node))))
+	nodePos := v.runConfig.Fset.Position(pos)
+	// The end of the text must be followed by something that isn't part
+	// of it. \b doesn't work for text that ends with a quote.
+	end := `\b`
+	if last := text[len(text)-1]; last == '"' || last == '\'' || last == '`' {
+		end = `(\s|$)`
+	}
+	nolint := regexp.MustCompile(fmt.Sprintf(`^//\s?permit:%s%s`, regexp.QuoteMeta(text), end))
 	for _, c := range v.comments {
 		commentPos := v.runConfig.Fset.Position(c.Pos())
 		if commentPos.Line == nodePos.Line && len(c.List) > 0 && nolint.MatchString(c.List[0].Text) {
@@ -221,6 +221,725 @@ const Bad Foo = 1;
 `, "use of `Foo` forbidden by pattern `Foo` at testing.go:5:11")
 	})
 
+	t.Run("it matches both the alias and the resolved type by default", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`^os\.FileMode\.IsDir$`, `^fs\.FileMode\.IsRegular$`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "os"
+
+func foo(mode os.FileMode) {
+	mode.IsDir()
+	mode.IsRegular()
+}`, "use of `mode.IsDir` forbidden by pattern `^os\\.FileMode\\.IsDir$` at testing.go:7:2",
+			"use of `mode.IsRegular` forbidden by pattern `^fs\\.FileMode\\.IsRegular$` at testing.go:8:2")
+	})
+
+	t.Run("it matches only the resolved type if requested", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: ^os\.FileMode\.IsDir$, alias: resolved}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "os"
+
+func foo(mode os.FileMode) {
+	mode.IsDir()
+}`)
+	})
+
+	t.Run("it matches only the alias if requested", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: FileMode, pkg: ^(os|io/fs)$, alias: only}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"io/fs"
+	"os"
+)
+
+var a os.FileMode
+var b fs.FileMode
+`, "use of `os.FileMode` forbidden by pattern `FileMode` at testing.go:9:7")
+	})
+
+	t.Run("it distinguishes calls from other usages", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: ^fmt\.Printf$, usage: call}`, `{p: ^fmt\.Println$, usage: value}`})
+		expectIssues(t, linter, false, `
+package bar
+
+func foo() {
+	fmt.Printf("here i am")
+	f := fmt.Printf
+	(fmt.Println)("here i am")
+	g := fmt.Println
+}`, "use of `fmt.Printf` forbidden by pattern `^fmt\\.Printf$` at testing.go:5:2",
+			"use of `fmt.Println` forbidden by pattern `^fmt\\.Println$` at testing.go:8:7")
+	})
+
+	t.Run("it distinguishes method values and method expressions", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{p: ^sql\.DB\.Exec$, usage: value}`,
+			`{p: ^sql\.DB\.Query$, usage: expression}`,
+		}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "database/sql"
+
+func foo(db *sql.DB) {
+	db.Exec("")
+	exec := db.Exec
+	db.Query("")
+	query := (*sql.DB).Query
+	(*sql.DB).Query(db, "")
+	_, _ = exec, query
+}`, "use of `db.Exec` forbidden by pattern `^sql\\.DB\\.Exec$` at testing.go:8:10",
+			"use of `(*sql.DB).Query` forbidden by pattern `^sql\\.DB\\.Query$` at testing.go:10:11",
+			"use of `(*sql.DB).Query` forbidden by pattern `^sql\\.DB\\.Query$` at testing.go:11:2")
+	})
+
+	t.Run("it finds forbidden cgo symbols", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`^C\.free$`, `^C\.char$`, `{kind: literal, p: ^_Cfunc_free$}`})
+		expectIssues(t, linter, false, cgoSource, "use of `C.char` forbidden by pattern `^C\\.char$` at testing.go:11:8",
+			"use of `C.free` forbidden by pattern `^C\\.free$` at testing.go:12:27",
+			"use of `\"_Cfunc_free\"` forbidden by pattern `^_Cfunc_free$` at testing.go:13:13")
+	})
+
+	t.Run("it finds forbidden cgo symbols with type information", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: ^C\.CString$, pkg: ^C$}`, `{p: ^C\.char$, pkg: ^C$}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, cgoSource, "use of `C.CString` forbidden by pattern `^C\\.CString$` at testing.go:10:7",
+			"use of `C.char` forbidden by pattern `^C\\.char$` at testing.go:11:8")
+	})
+
+	t.Run("it finds forbidden compiler directives", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: directive, p: ^go:linkname\b}`, `{kind: directive, p: ^go:noinline$, msg: trust the compiler}`})
+		expectIssues(t, linter, false, `
+package bar
+
+import _ "unsafe"
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//go:noinline
+func foo() {}
+
+// go:noinline is not a directive because of the space
+func bar() {}
+`, "use of `//go:linkname` forbidden by pattern `^go:linkname\\b` at testing.go:6:1",
+			"use of `//go:noinline` forbidden because \"trust the compiler\" at testing.go:9:1")
+	})
+
+	t.Run("allows explicitly permitting otherwise forbidden compiler directives", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: directive, p: ^go:linkname\b}`})
+		expectIssues(t, linter, false, `
+package bar
+
+import _ "unsafe"
+
+//permit://go:linkname
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+`)
+	})
+
+	t.Run("it doesn't treat permit and nolint comments as compiler directives", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: directive, p: linkname}`})
+		expectIssues(t, linter, false, `
+package bar
+
+import _ "unsafe"
+
+//permit:go:linkname
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//nolint:linkname
+//go:linkname walltime runtime.walltime
+func walltime() (int64, int32)
+`, "use of `//go:linkname` forbidden by pattern `linkname` at testing.go:11:1")
+	})
+
+	t.Run("it ignores compiler directives generated by cgo", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: directive, p: .}`})
+		expectIssues(t, linter, true, cgoSource)
+	})
+
+	t.Run("it finds forbidden literals", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: literal, p: '^localhost:'}`, `{kind: literal, p: '^0o?777$', msg: too permissive}`})
+		expectIssues(t, linter, false, `
+package bar
+
+import "os"
+
+const addr = "localhost:8080"
+
+func foo() {
+	os.Chmod("/tmp/localhost:8080", 0777)
+	os.Chmod("localhost:8080", 0755) //permit:"localhost:8080"
+}`, "use of `\"localhost:8080\"` forbidden by pattern `^localhost:` at testing.go:6:14",
+			"use of `0777` forbidden because \"too permissive\" at testing.go:9:34")
+	})
+
+	t.Run("it finds forbidden literals passed to a function", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: literal, p: SELECT \*, call: ^sql\.DB\.Query$, arg: 0}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func foo(db *sql.DB) {
+	db.Query("SELECT * FROM users")
+	db.Query("SELECT id FROM users")
+	fmt.Println("SELECT * FROM users")
+}`, "use of `\"SELECT * FROM users\"` forbidden by pattern `SELECT \\*` at testing.go:10:11")
+	})
+
+	t.Run("it finds forbidden struct tags", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: tag, key: ^json$, p: ',string\b'}`, `{kind: tag, key: ^gorm$, p: \bembedded\b}`})
+		expectIssues(t, linter, false, `
+package bar
+
+type Foo struct {
+	A int `+"`json:\"a,string\"`"+`
+	B int `+"`json:\"b\" gorm:\"embedded\"`"+`
+	C int `+"`json:\"c,string\"`"+` //permit:json:"c,string"
+	D int `+"`xml:\"d,string\"`"+`
+}`, "use of `json:\"a,string\"` forbidden by pattern `,string\\b` at testing.go:5:8",
+			"use of `gorm:\"embedded\"` forbidden by pattern `\\bembedded\\b` at testing.go:6:8")
+	})
+
+	t.Run("it finds forbidden go:generate commands", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: generate, p: '^go run \S+@latest\b', msg: pin the version}`})
+		expectIssues(t, linter, false, `
+package bar
+
+//go:generate go run example.com/gen@latest -out gen.go
+//go:generate go run example.com/gen@v1.2.3 -out gen.go
+//go:generate -command gen go run example.com/gen@latest
+//go:generate gen -out gen2.go
+
+//permit://go:generate
+//go:generate go run example.com/other@latest
+`, "use of `go run example.com/gen@latest -out gen.go` forbidden because \"pin the version\" at testing.go:4:1",
+			"use of `gen -out gen2.go` forbidden because \"pin the version\" at testing.go:7:1")
+	})
+
+	t.Run("it restricts matches to enclosing functions", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{p: ^os\.Exit$, not_inside: ^main$}`,
+			`{p: ^panic$, inside: ^T\.Method$}`,
+			`{p: ^println$, inside: \.func1$}`,
+		})
+		expectIssues(t, linter, false, `
+package bar
+
+func main() {
+	os.Exit(1)
+	func() {
+		os.Exit(1)
+	}()
+}
+
+type T struct{}
+
+func (t *T) Method() {
+	panic("here i am")
+	func() {
+		println("here i am")
+		os.Exit(1)
+	}()
+}
+
+func foo() {
+	panic("here i am")
+	println("here i am")
+}`, "use of `panic` forbidden by pattern `^panic$` at testing.go:14:2",
+			"use of `println` forbidden by pattern `^println$` at testing.go:16:3",
+			"use of `os.Exit` forbidden by pattern `^os\\.Exit$` at testing.go:17:3")
+	})
+
+	t.Run("it numbers nested function literals like the compiler", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: ^println$, inside: ^foo\.func1\.2$}`})
+		expectIssues(t, linter, false, `
+package bar
+
+func foo() {
+	func() {
+		func() {
+			println("here i am")
+		}()
+		func() {
+			println("here i am")
+			func() {
+				println("here i am")
+			}()
+		}()
+	}()
+	func() {
+		println("here i am")
+	}()
+}`, "use of `println` forbidden by pattern `^println$` at testing.go:10:4",
+			"use of `println` forbidden by pattern `^println$` at testing.go:12:5")
+	})
+
+	t.Run("it names function literals in package-level variables after the variable", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{p: ^os\.Exit$, inside: ^hook$}`,
+			`{p: ^panic$, not_inside: ^(hook|wrapped)$}`,
+			`{p: ^println$, inside: ^wrapped\.func1$}`,
+		})
+		expectIssues(t, linter, false, `
+package bar
+
+var hook = func() {
+	os.Exit(1)
+	panic("here i am")
+}
+
+var wrapped = wrap(func() {
+	println("here i am")
+	panic("here i am")
+})
+
+func wrap(f func()) func() { return f }
+
+func foo() {
+	os.Exit(1)
+	panic("here i am")
+	println("here i am")
+}`, "use of `os.Exit` forbidden by pattern `^os\\.Exit$` at testing.go:5:2",
+			"use of `println` forbidden by pattern `^println$` at testing.go:10:2",
+			"use of `panic` forbidden by pattern `^panic$` at testing.go:18:2")
+	})
+
+	t.Run("it matches enclosing functions with package path", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: ^fmt\.Println$, not_inside: ^_/.*\.allowed$}`})
+		expectIssues(t, linter, false, `
+package bar
+
+func allowed() {
+	fmt.Println("here i am")
+}
+
+func forbidden() {
+	fmt.Println("here i am")
+}`, "use of `fmt.Println` forbidden by pattern `^fmt\\.Println$` at testing.go:9:2")
+	})
+
+	t.Run("it restricts matches to statement contexts", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{p: ^time\.After$, context: loop}`,
+			`{p: ^recover$, not_context: defer}`,
+			`{p: ^http\.Get$, context: init}`,
+			`{p: ^net\.Dial$, context: global_var}`,
+			`{p: ^println$, context: go}`,
+		})
+		expectIssues(t, linter, false, `
+package bar
+
+var conn, _ = net.Dial("tcp", "localhost:80")
+
+func init() {
+	http.Get("http://localhost")
+}
+
+func foo() {
+	var conn, _ = net.Dial("tcp", "localhost:80")
+	http.Get("http://localhost")
+	for range time.After(1) {
+		<-time.After(1)
+	}
+	defer func() {
+		recover()
+	}()
+	recover()
+	go println("here i am")
+	println("here i am")
+}`, "use of `net.Dial` forbidden by pattern `^net\\.Dial$` at testing.go:4:15",
+			"use of `http.Get` forbidden by pattern `^http\\.Get$` at testing.go:7:2",
+			"use of `time.After` forbidden by pattern `^time\\.After$` at testing.go:14:5",
+			"use of `recover` forbidden by pattern `^recover$` at testing.go:19:2",
+			"use of `println` forbidden by pattern `^println$` at testing.go:20:5")
+	})
+
+	t.Run("it distinguishes reads, writes and taking the address", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{p: ^http\.DefaultTransport$, access: write}`,
+			`{p: ^http\.DefaultClient$, access: address}`,
+			`{p: ^http\.Client\.Timeout$, access: write}`,
+		}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "net/http"
+
+func foo(c *http.Client) {
+	t := http.DefaultTransport
+	http.DefaultTransport = t
+	http.DefaultClient.Timeout = 1
+	_ = &http.DefaultClient
+	_ = c.Timeout
+	c.Timeout++
+}`, "use of `http.DefaultTransport` forbidden by pattern `^http\\.DefaultTransport$` at testing.go:8:2",
+			"use of `http.DefaultClient.Timeout` forbidden by pattern `^http\\.Client\\.Timeout$` at testing.go:9:2",
+			"use of `http.DefaultClient` forbidden by pattern `^http\\.DefaultClient$` at testing.go:10:7",
+			"use of `c.Timeout` forbidden by pattern `^http\\.Client\\.Timeout$` at testing.go:12:2")
+	})
+
+	t.Run("it finds types which are not constructed via their constructor", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: construct, p: ^bytes\.Buffer$, via: bytes.NewBuffer}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "bytes"
+
+var a bytes.Buffer
+
+func foo() {
+	b := bytes.Buffer{}
+	c := &bytes.Buffer{}
+	d := new(bytes.Buffer)
+	e := bytes.NewBuffer(nil)
+	var f *bytes.Buffer
+	var g = bytes.NewBuffer(nil)
+	h := bytes.Buffer{} //permit:bytes.Buffer
+	_, _, _, _, _, _, _ = b, c, d, e, f, g, h
+	i := []bytes.Buffer{{}}
+	j := []*bytes.Buffer{{}, nil}
+	k := map[string]bytes.Buffer{"x": {}}
+	var l [2]bytes.Buffer
+	m := new([2]bytes.Buffer)
+	n := []*bytes.Buffer{bytes.NewBuffer(nil)}
+	_, _, _, _, _, _ = i, j, k, l, m, n
+	o := [2]bytes.Buffer{}
+	q := [1]bytes.Buffer{{}}
+	_, _ = o, q
+}`, "use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:6:5",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:9:7",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:10:8",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:11:7",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:17:22",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:18:23",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:19:36",
+			"use of `[2]bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:20:6",
+			"use of `[2]bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:21:7",
+			"use of `[2]bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:24:7",
+			"use of `bytes.Buffer` forbidden because \"must be constructed via bytes.NewBuffer\" at testing.go:25:23")
+	})
+
+	t.Run("it restricts construct patterns to statement contexts", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: construct, p: ^bytes\.Buffer$, context: loop}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "bytes"
+
+func foo() {
+	var a bytes.Buffer
+	for range 3 {
+		var b bytes.Buffer
+		_ = b
+	}
+	_ = a
+}`, "use of `bytes.Buffer` forbidden by pattern `^bytes\\.Buffer$` at testing.go:9:7")
+	})
+
+	t.Run("it finds forbidden comparisons", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{kind: compare, p: ^error$, msg: use errors.Is}`,
+			`{kind: compare, p: ^time\.Time$}`,
+		}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"io"
+	"time"
+)
+
+func foo(err error, a, b time.Time) bool {
+	if err == nil || err != io.EOF {
+		return a == b
+	}
+	switch err {
+	case nil, io.ErrUnexpectedEOF:
+	}
+	return a.Equal(b)
+}`, "use of `err != io.EOF` forbidden because \"use errors.Is\" at testing.go:10:19",
+			"use of `a == b` forbidden by pattern `^time\\.Time$` at testing.go:11:10",
+			"use of `case io.ErrUnexpectedEOF` forbidden because \"use errors.Is\" at testing.go:14:12")
+	})
+
+	t.Run("it finds forbidden type assertions", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{kind: assert, p: ^fs\.PathError$, msg: use errors.As}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"io"
+	"os"
+)
+
+func foo(err error) {
+	_, _ = err.(*os.PathError)
+	_, _ = err.(interface{ Timeout() bool })
+	switch err.(type) {
+	case *os.PathError:
+	case io.Reader:
+	}
+}`, "use of `err.(*os.PathError)` forbidden because \"use errors.As\" at testing.go:10:9",
+			"use of `case *os.PathError` forbidden because \"use errors.As\" at testing.go:13:7")
+	})
+
+	t.Run("it finds calls of functions with forbidden signatures", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{kind: signature, params: '^.+, context\.Context'}`,
+			`{kind: signature, p: '^bar\.[A-Z]', results: '^(interface\{\}|any)$'}`,
+			`{kind: signature, args: '\bunsafe\.Pointer\b', msg: do not pass unsafe pointers}`,
+		}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"context"
+	"unsafe"
+)
+
+func good(ctx context.Context, s string) {}
+func bad(s string, ctx context.Context)  {}
+func Get() any                           { return nil }
+func get() any                           { return nil }
+func use(p interface{})                  {}
+
+func foo(ctx context.Context, x int) {
+	good(ctx, "here i am")
+	bad("here i am", ctx)
+	_, _ = Get(), get()
+	use(unsafe.Pointer(&x))
+	use(&x)
+}`, "use of `bad` forbidden by pattern `{params: ^.+, context\\.Context}` at testing.go:17:2",
+			"use of `Get` forbidden by pattern `{p: ^bar\\.[A-Z], results: ^(interface\\{\\}|any)$}` at testing.go:18:9",
+			"use of `use` forbidden because \"do not pass unsafe pointers\" at testing.go:19:2")
+	})
+
+	t.Run("it finds calls whose result gets discarded", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: ^bufio\.Writer\.Flush$, discarded: true}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "bufio"
+
+func foo(w *bufio.Writer) error {
+	w.Flush()
+	_ = w.Flush()
+	defer w.Flush()
+	go w.Flush()
+	err := w.Flush()
+	_, err = w.Buffered(), w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	f := w.Flush
+	_ = f
+	var _ = w.Flush()
+	var _, _ = w.Buffered(), w.Flush()
+	var n, _ = w.Buffered(), w.Flush()
+	var _, m = w.Buffered(), w.Flush()
+	_, _ = n, m
+	return w.Flush()
+}`, "use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:7:2",
+			"use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:8:6",
+			"use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:9:8",
+			"use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:10:5",
+			"use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:18:10",
+			"use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:19:27",
+			"use of `w.Flush` forbidden by pattern `^bufio\\.Writer\\.Flush$` at testing.go:20:27")
+	})
+
+	t.Run("it finds forbidden constant values", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`{const: crypto/tls.VersionTLS10, type: ^uint16$, msg: TLS 1.0 is insecure}`,
+			`{type: ^(fs|os)\.FileMode$, value: '&0o002'}`,
+			`{kind: constant, p: ^time\.Nanosecond$}`,
+		}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"crypto/tls"
+	"os"
+	"time"
+)
+
+const world os.FileMode = 0o777
+
+func foo() {
+	_ = &tls.Config{MinVersion: tls.VersionTLS10}
+	_ = &tls.Config{MinVersion: 0x0301}
+	_ = &tls.Config{MinVersion: tls.VersionTLS12}
+	_ = os.WriteFile("x", nil, 0o666)
+	_ = os.WriteFile("x", nil, 0o644)
+	_ = os.Mkdir("x", (os.ModePerm))
+	_ = time.Duration(1) * time.Nanosecond
+	_ = time.Nanosecond
+	_ = 0x0301
+}`, "use of `0o777` forbidden by pattern `{type: ^(fs|os)\\.FileMode$, value: &0o002}` at testing.go:10:27",
+			"use of `tls.VersionTLS10` forbidden because \"TLS 1.0 is insecure\" at testing.go:13:30",
+			"use of `0x0301` forbidden because \"TLS 1.0 is insecure\" at testing.go:14:30",
+			"use of `0o666` forbidden by pattern `{type: ^(fs|os)\\.FileMode$, value: &0o002}` at testing.go:16:29",
+			"use of `os.ModePerm` forbidden by pattern `{type: ^(fs|os)\\.FileMode$, value: &0o002}` at testing.go:18:21",
+			"use of `time.Nanosecond` forbidden by pattern `{p: ^time\\.Nanosecond$}` at testing.go:20:6")
+	})
+
+	t.Run("it finds identifiers from packages that are not on the allowlist", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{pkg: ^unsafe$, p: ^unsafe\.(Sizeof|Alignof)$, allow_only: true}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "unsafe"
+
+type Pointer struct{}
+
+func foo(x int) (uintptr, uintptr, unsafe.Pointer, Pointer) {
+	return unsafe.Sizeof(x), unsafe.Alignof(x), unsafe.Pointer(&x), Pointer{}
+}`, "use of `unsafe.Pointer` forbidden by pattern `{pkg: ^unsafe$, p: ^unsafe\\.(Sizeof|Alignof)$, allow_only: true}` at testing.go:8:36",
+			"use of `unsafe.Pointer` forbidden by pattern `{pkg: ^unsafe$, p: ^unsafe\\.(Sizeof|Alignof)$, allow_only: true}` at testing.go:9:46")
+	})
+
+	t.Run("it finds uses of layers that are not allowed", func(t *testing.T) {
+		linter, err := NewLinter([]string{
+			`{kind: layer, name: core, pkg: ^(errors|strings)$}`,
+			`{kind: layer, name: system, pkg: ^(os|net)(/|$)}`,
+			`{kind: layer, name: app, pkg: ., uses: [core]}`,
+		}, OptionAnalyzeTypes(true))
+		require.NoError(t, err)
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func foo() error {
+	fmt.Println(strings.ToUpper(os.Getenv("HOME")))
+	var _ os.FileMode
+	_ = os.Getenv //permit:os.Getenv
+	return errors.New("here i am")
+}`, "use of `os.Getenv` forbidden because \"layer app must not use layer system\" at testing.go:12:30",
+			"use of `os.FileMode` forbidden because \"layer app must not use layer system\" at testing.go:13:8")
+	})
+
+	t.Run("it finds methods of types that implement an interface", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: \.Close$, implements: io.Closer, inside: ^handle$}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"io"
+	"os"
+)
+
+type conn struct{}
+
+func (c *conn) Close() error { return nil }
+
+type door struct{}
+
+func (d door) Close() {}
+
+func handle(f *os.File, c conn, r io.ReadCloser, d door) {
+	f.Close()
+	c.Close()
+	r.Close()
+	d.Close()
+}
+
+func other(f *os.File) {
+	f.Close()
+}`, "use of `f.Close` forbidden by pattern `\\.Close$` at testing.go:18:2",
+			"use of `c.Close` forbidden by pattern `\\.Close$` at testing.go:19:2",
+			"use of `r.Close` forbidden by pattern `\\.Close$` at testing.go:20:2")
+	})
+
+	t.Run("it resolves interfaces of the universe scope", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`{p: \.Error$, implements: error}`}, OptionAnalyzeTypes(true))
+		expectIssues(t, linter, true, `
+package bar
+
+type failure struct{}
+
+func (f failure) Error() string { return "failure" }
+
+type report struct{}
+
+func (r report) Error() {}
+
+func foo(err error, f failure, r report) {
+	_ = err.Error()
+	_ = f.Error()
+	r.Error()
+}`, "use of `err.Error` forbidden by pattern `\\.Error$` at testing.go:13:6",
+			"use of `f.Error` forbidden by pattern `\\.Error$` at testing.go:14:6")
+	})
+
+	t.Run("it finds methods and fields used via reflection", func(t *testing.T) {
+		linter, _ := NewLinter([]string{
+			`^sql\.DB\.Exec$`,
+			`{p: ^bar\.config\.secret$, access: read}`,
+		}, OptionAnalyzeTypes(true), OptionAnalyzeReflection(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+type config struct{ secret, public string }
+
+func foo(db *sql.DB, c *config, name string) {
+	reflect.ValueOf(db).MethodByName("Exec")
+	reflect.TypeOf(db).MethodByName("Exec")
+	reflect.ValueOf(db).MethodByName("Query")
+	reflect.ValueOf(db).MethodByName(name)
+	reflect.ValueOf(c).Elem().FieldByName("secret")
+	reflect.Indirect(reflect.ValueOf(c)).FieldByName("public")
+	v := reflect.ValueOf(db)
+	v.MethodByName("Exec")
+	var t = reflect.TypeOf(c).Elem()
+	t.FieldByName("secret")
+	w := reflect.ValueOf(db)
+	w = reflect.ValueOf(c)
+	w.MethodByName("Exec")
+}`, "use of `reflect.ValueOf(db).MethodByName(\"Exec\")` forbidden by pattern `^sql\\.DB\\.Exec$` at testing.go:12:2",
+			"use of `reflect.TypeOf(db).MethodByName(\"Exec\")` forbidden by pattern `^sql\\.DB\\.Exec$` at testing.go:13:2",
+			"use of `reflect.ValueOf(c).Elem().FieldByName(\"secret\")` forbidden by pattern `^bar\\.config\\.secret$` at testing.go:16:2",
+			"use of `v.MethodByName(\"Exec\")` forbidden by pattern `^sql\\.DB\\.Exec$` at testing.go:19:2",
+			"use of `t.FieldByName(\"secret\")` forbidden by pattern `^bar\\.config\\.secret$` at testing.go:21:2")
+	})
+
+	t.Run("it stops at cycles of variables used via reflection", func(t *testing.T) {
+		linter, _ := NewLinter([]string{`^sql\.DB\.Exec$`}, OptionAnalyzeTypes(true), OptionAnalyzeReflection(true))
+		expectIssues(t, linter, true, `
+package bar
+
+import "reflect"
+
+var a reflect.Value = reflect.Indirect(b)
+var b reflect.Value = reflect.Indirect(a)
+
+func foo() {
+	a.MethodByName("Exec")
+}`)
+	})
+
 	t.Run("it ignores import alises", func(t *testing.T) {
 		linter, _ := NewLinter([]string{`Foo`}, OptionAnalyzeTypes(true))
 		expectIssues(t, linter, true, `
@@ -231,6 +950,46 @@ import Foo "foo"
 	})
 }
 
+// BenchmarkRunWithConfig measures how long it takes to check a large package
+// with type information. Only an identifier pattern is used, so checks for
+// other kinds of patterns should not add any overhead.
+func BenchmarkRunWithConfig(b *testing.B) {
+	linter, err := NewLinter([]string{`^fmt\.Print`}, OptionAnalyzeTypes(true))
+	require.NoError(b, err)
+	cfg := packages.Config{
+		Mode: packages.NeedSyntax | packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps,
+	}
+	pkgs, err := packages.Load(&cfg, "go/types")
+	require.NoError(b, err)
+	require.Len(b, pkgs, 1)
+	p := pkgs[0]
+	nodes := make([]ast.Node, 0, len(p.Syntax))
+	for _, n := range p.Syntax {
+		nodes = append(nodes, n)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := linter.RunWithConfig(RunConfig{Fset: p.Fset, TypesInfo: p.TypesInfo, PkgPath: p.PkgPath}, nodes...); err != nil {
+			b.Fatalf("failed: %s", err)
+		}
+	}
+}
+
+const cgoSource = `
+package bar
+
+// #include <stdlib.h>
+import "C"
+
+import "unsafe"
+
+func foo() {
+	p := C.CString("here i am")
+	var c C.char = *p
+	C.free(unsafe.Pointer(p))
+	println(c, "_Cfunc_free")
+}`
+
 // sourcePath matches "at /tmp/TestForbiddenIdentifiersdisplays_custom_messages4260088387/001/testing.go".
 var sourcePath = regexp.MustCompile(`at .*/([[:alnum:]]+.go)`)
 
@@ -286,7 +1045,7 @@ func parseFile(t *testing.T, linter *Linter, expand bool, fileName, contents str
 		for _, n := range p.Syntax {
 			nodes = append(nodes, n)
 		}
-		newIssues, err := linter.RunWithConfig(RunConfig{Fset: p.Fset, TypesInfo: p.TypesInfo, DebugLog: t.Logf}, nodes...)
+		newIssues, err := linter.RunWithConfig(RunConfig{Fset: p.Fset, TypesInfo: p.TypesInfo, PkgPath: p.PkgPath, DebugLog: t.Logf}, nodes...)
 		if err != nil {
 			t.Fatalf("failed: %s", err)
 		}
@@ -0,0 +1,172 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+)
+
+// scopeNames contains the names of all functions in a file and of the types
+// which are declared inside those functions.
+type scopeNames struct {
+	// funcs contains the names of *ast.FuncDecl and *ast.FuncLit nodes.
+	// Methods are named `<type name>.<method name>`. Function literals get
+	// numbered like the Go compiler does, for example `Foo.func1` for the
+	// first function literal inside `Foo` and `Foo.func1.1` for the first
+	// one inside that.
+	funcs map[ast.Node]string
+
+	// vars contains the names of the package-level variables which get
+	// initialized by the expressions that are used as keys. These act as
+	// the enclosing function for the function literals inside them, which
+	// get named like `hook.func1`.
+	vars map[ast.Node]string
+
+	// types contains the names of the functions enclosing local types,
+	// indexed by the position of the type name.
+	types map[token.Pos]string
+}
+
+// scopeNames returns the names for the root node, collecting them first if
+// necessary.
+func (v *visitor) scopeNames() *scopeNames {
+	if v.names == nil {
+		v.names = collectScopeNames(v.root)
+	}
+	return v.names
+}
+
+func collectScopeNames(root ast.Node) *scopeNames {
+	names := &scopeNames{
+		funcs: map[ast.Node]string{},
+		vars:  map[ast.Node]string{},
+		types: map[token.Pos]string{},
+	}
+	// Function literals inside other function literals get numbered
+	// without the "func" prefix, for example `Foo.func1.1`.
+	var walk func(node ast.Node, funcName string, nested bool)
+	walk = func(node ast.Node, funcName string, nested bool) {
+		numFuncLits := 0
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				numFuncLits++
+				name := fmt.Sprintf("%s.func%d", funcName, numFuncLits)
+				if nested {
+					name = fmt.Sprintf("%s.%d", funcName, numFuncLits)
+				}
+				names.funcs[n] = name
+				walk(n.Body, name, true)
+				return false
+			case *ast.TypeSpec:
+				names.types[n.Name.Pos()] = funcName
+			}
+			return true
+		})
+	}
+
+	file, ok := root.(*ast.File)
+	if !ok {
+		return names
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Body == nil {
+				continue
+			}
+			funcName := decl.Name.Name
+			if recvName := receiverTypeName(decl); recvName != "" {
+				funcName = recvName + "." + funcName
+			}
+			names.funcs[decl] = funcName
+			walk(decl.Body, funcName, false)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || decl.Tok != token.VAR {
+					continue
+				}
+				for i, value := range valueSpec.Values {
+					// With a single value for several variables, as in
+					// `var a, b = f()`, the first variable is used.
+					varName := valueSpec.Names[0].Name
+					if len(valueSpec.Values) == len(valueSpec.Names) {
+						varName = valueSpec.Names[i].Name
+					}
+					names.vars[value] = varName
+					walk(value, varName, false)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// localName returns the name of a type name, prefixed with the name of
+// the enclosing function if it is not declared at the package level.
+func (v *visitor) localName(obj *types.TypeName) string {
+	if obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope() {
+		return obj.Name()
+	}
+	if funcName, ok := v.scopeNames().types[obj.Pos()]; ok {
+		return funcName + "." + obj.Name()
+	}
+	return obj.Name()
+}
+
+// receiverTypeName returns the name of the receiver type of a method, without
+// pointer and type parameters, or an empty string for functions.
+func receiverTypeName(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return ""
+	}
+	expr := funcDecl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch x := expr.(type) {
+	case *ast.IndexExpr:
+		expr = x.X
+	case *ast.IndexListExpr:
+		expr = x.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// enclosingFuncNames returns the names of all functions which enclose the
+// current node, from the innermost to the outermost one. Each name is
+// returned as is and, if the package path is known, with the package path
+// as prefix, for example `example.com/some/pkg.Foo`.
+func (v *visitor) enclosingFuncNames() []string {
+	var names []string
+	for i := len(v.stack) - 1; i >= 0; i-- {
+		for _, scope := range []map[ast.Node]string{v.scopeNames().funcs, v.scopeNames().vars} {
+			name, ok := scope[v.stack[i]]
+			if !ok {
+				continue
+			}
+			names = append(names, name)
+			if v.runConfig.PkgPath != "" {
+				names = append(names, v.runConfig.PkgPath+"."+name)
+			}
+		}
+	}
+	return names
+}
+
+// matchesEnclosingFunc checks the `inside` and `not_inside` restrictions of
+// a pattern.
+func (v *visitor) matchesEnclosingFunc(p *pattern) bool {
+	if p.Inside == "" && p.NotInside == "" {
+		return true
+	}
+	names := v.enclosingFuncNames()
+	return (p.Inside == "" || slices.ContainsFunc(names, p.insideRe.MatchString)) &&
+		(p.NotInside == "" || !slices.ContainsFunc(names, p.notInsideRe.MatchString))
+}
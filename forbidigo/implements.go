@@ -0,0 +1,51 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// implements checks whether the node is a selector like `x.Close` where the
+// type of `x` implements the interface with the given full name. Values
+// of other types than pointers and interfaces also implement the interface
+// if the pointer to them does, because such methods can be called on
+// addressable values.
+func (v *visitor) implements(node ast.Node, name string) bool {
+	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil {
+		return false
+	}
+	selector, ok := node.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	// Qualified identifiers like `os.Exit` have no type for the package.
+	typeAndValue, ok := v.runConfig.TypesInfo.Types[selector.X]
+	if !ok {
+		return false
+	}
+	t := typeAndValue.Type
+	iface, err := asInterface(v.lookupObject(name))
+	if err != nil {
+		return false
+	}
+	if types.Implements(t, iface) {
+		return true
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return false
+	default:
+		return types.Implements(types.NewPointer(t), iface)
+	}
+}
+
+// asInterface returns the interface that the object names.
+func asInterface(object types.Object) (*types.Interface, error) {
+	if typeName, ok := object.(*types.TypeName); ok {
+		if iface, ok := typeName.Type().Underlying().(*types.Interface); ok {
+			return iface, nil
+		}
+	}
+	return nil, fmt.Errorf("not an interface")
+}
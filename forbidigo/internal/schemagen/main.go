@@ -0,0 +1,220 @@
+// schemagen generates the JSON Schema for configuration files from the
+// source code of the forbidigo package: the types of the fields, their
+// names in YAML and their documentation come from the structs, the allowed
+// values from the constants documented as "Supported values for
+// pattern.<field>".
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// schema is the subset of JSON Schema that gets generated. The order of
+// the fields is the order in the output.
+type schema struct {
+	Schema               string      `json:"$schema,omitempty"`
+	Title                string      `json:"title,omitempty"`
+	Ref                  string      `json:"$ref,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	Type                 string      `json:"type,omitempty"`
+	Enum                 []string    `json:"enum,omitempty"`
+	Items                *schema     `json:"items,omitempty"`
+	Properties           *properties `json:"properties,omitempty"`
+	AdditionalProperties *bool       `json:"additionalProperties,omitempty"`
+	OneOf                []*schema   `json:"oneOf,omitempty"`
+	Defs                 *properties `json:"$defs,omitempty"`
+}
+
+// properties is a JSON object which keeps the order of its entries.
+type properties struct {
+	names   []string
+	schemas map[string]*schema
+}
+
+func (p *properties) add(name string, s *schema) {
+	if p.schemas == nil {
+		p.schemas = map[string]*schema{}
+	}
+	p.names = append(p.names, name)
+	p.schemas[name] = s
+}
+
+func (p *properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		value, err := json.Marshal(p.schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// supportedValues matches the documentation of constants with the values
+// for fields of the pattern struct.
+var supportedValues = regexp.MustCompile(`^Supported values for (pattern\.\w+(?: and pattern\.\w+)*)\.`)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the forbidigo package")
+	out := flag.String("o", "schema.json", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, *dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkg, ok := pkgs["forbidigo"]
+	if !ok {
+		log.Fatalf("no forbidigo package in %s", *dir)
+	}
+
+	structs := map[string]*ast.StructType{}
+	enums := map[string][]string{}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			switch decl.Tok {
+			case token.TYPE:
+				for _, spec := range decl.Specs {
+					spec := spec.(*ast.TypeSpec)
+					if st, ok := spec.Type.(*ast.StructType); ok {
+						structs[spec.Name.Name] = st
+					}
+				}
+			case token.CONST:
+				match := supportedValues.FindStringSubmatch(decl.Doc.Text())
+				if match == nil {
+					continue
+				}
+				var values []string
+				for _, spec := range decl.Specs {
+					for _, value := range spec.(*ast.ValueSpec).Values {
+						if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							s, _ := strconv.Unquote(lit.Value)
+							values = append(values, s)
+						}
+					}
+				}
+				for _, field := range strings.Split(match[1], " and ") {
+					enums[strings.TrimPrefix(field, "pattern.")] = values
+				}
+			}
+		}
+	}
+
+	g := generator{structs: structs, enums: enums}
+	root := g.object("Config")
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "forbidigo configuration"
+	root.Defs = &properties{}
+	root.Defs.add("pattern", &schema{
+		Description: "A regular expression or a struct with the regular expression and further options.",
+		OneOf: []*schema{
+			{Type: "string", Description: "A regular expression for the code that is forbidden."},
+			g.object("pattern"),
+		},
+	})
+	root.Defs.add("layer", g.object("Layer"))
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	structs map[string]*ast.StructType
+	enums   map[string][]string
+}
+
+// object generates the schema for a struct. Only fields with a YAML name
+// are included.
+func (g generator) object(name string) *schema {
+	st, ok := g.structs[name]
+	if !ok {
+		log.Fatalf("struct %s not found", name)
+	}
+	closed := false
+	s := &schema{Type: "object", Properties: &properties{}, AdditionalProperties: &closed}
+	var groupDoc string
+	for _, field := range st.Fields.List {
+		if field.Tag == nil || len(field.Names) == 0 {
+			continue
+		}
+		tag, _ := strconv.Unquote(field.Tag.Value)
+		yamlName, _, _ := strings.Cut(reflect.StructTag(tag).Get("yaml"), ",")
+		if yamlName == "" || yamlName == "-" {
+			continue
+		}
+		fieldSchema := g.typeSchema(field.Type)
+		doc := field.Doc.Text()
+		if field.Comment != nil {
+			doc = field.Comment.Text()
+		} else if field.Doc == nil {
+			// Fields without documentation share the one of the
+			// group that they belong to.
+			doc = groupDoc
+		} else {
+			groupDoc = doc
+		}
+		fieldSchema.Description = strings.Join(strings.Fields(doc), " ")
+		fieldSchema.Enum = g.enums[field.Names[0].Name]
+		s.Properties.add(yamlName, fieldSchema)
+	}
+	return s
+}
+
+// typeSchema generates the schema for the type of a field.
+func (g generator) typeSchema(expr ast.Expr) *schema {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return g.typeSchema(expr.X)
+	case *ast.ArrayType:
+		return &schema{Type: "array", Items: g.typeSchema(expr.Elt)}
+	case *ast.Ident:
+		switch expr.Name {
+		case "string":
+			return &schema{Type: "string"}
+		case "bool":
+			return &schema{Type: "boolean"}
+		case "int":
+			return &schema{Type: "integer"}
+		case "yamlPattern":
+			return &schema{Ref: "#/$defs/pattern"}
+		case "Layer":
+			return &schema{Ref: "#/$defs/layer"}
+		}
+	}
+	log.Fatalf("unsupported field type %s", fmt.Sprint(expr))
+	return nil
+}
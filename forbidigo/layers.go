@@ -0,0 +1,90 @@
+package forbidigo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+)
+
+// validateLayers checks that layer names are unique and that layers only
+// use layers which exist.
+func validateLayers(patterns []*pattern) error {
+	names := map[string]bool{}
+	for _, p := range patterns {
+		if p.kind() != kindLayer {
+			continue
+		}
+		if names[p.Name] {
+			return fmt.Errorf("layer `%s` is defined more than once", p.Name)
+		}
+		names[p.Name] = true
+	}
+	for _, p := range patterns {
+		for _, name := range p.Uses {
+			if !names[name] {
+				return fmt.Errorf("layer `%s` uses unknown layer `%s`", p.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// layerFor returns the first layer whose pkg pattern matches the package
+// path, nil if the package doesn't belong to any layer.
+func (l *Linter) layerFor(pkgPath string) *pattern {
+	for _, p := range l.patterns {
+		if p.kind() == kindLayer && p.pkgRe.MatchString(pkgPath) {
+			return p
+		}
+	}
+	return nil
+}
+
+// checkLayers reports references to package-level objects from a layer that
+// the layer of the current package may not use. Packages in the same layer
+// and packages outside of all layers may always be used. Methods and fields
+// are not checked because using them doesn't require importing the package
+// that declares them.
+func (v *visitor) checkLayers(node ast.Node, srcText string) {
+	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil || v.runConfig.PkgPath == "" {
+		return
+	}
+	var object types.Object
+	switch node := node.(type) {
+	case *ast.Ident:
+		object = v.runConfig.TypesInfo.Uses[node]
+	case *ast.SelectorExpr:
+		if ident, ok := node.X.(*ast.Ident); ok {
+			if _, ok := v.runConfig.TypesInfo.Uses[ident].(*types.PkgName); ok {
+				object = v.runConfig.TypesInfo.Uses[node.Sel]
+			}
+		}
+	}
+	if object == nil || object.Pkg() == nil || object.Parent() != object.Pkg().Scope() {
+		return
+	}
+	pkgPath := object.Pkg().Path()
+	if pkgPath == v.runConfig.PkgPath {
+		return
+	}
+	from, to := v.linter.layerFor(v.runConfig.PkgPath), v.linter.layerFor(pkgPath)
+	if from == nil || to == nil || from == to || slices.Contains(from.Uses, to.Name) {
+		return
+	}
+	v.runConfig.DebugLog("%s: layer %s uses layer %s", v.runConfig.Fset.Position(node.Pos()), from.Name, to.Name)
+	if v.permitText(node.Pos(), srcText) {
+		return
+	}
+	msg := from.Msg
+	if msg == "" {
+		msg = fmt.Sprintf("layer %s must not use layer %s", from.Name, to.Name)
+	}
+	v.issues = append(v.issues, UsedIssue{
+		identifier: srcText,
+		pattern:    from.description(),
+		pos:        node.Pos(),
+		position:   v.runConfig.Fset.Position(node.Pos()),
+		customMsg:  msg,
+	})
+}
@@ -0,0 +1,68 @@
+package forbidigo
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// checkLiteral matches a literal against the patterns for literals.
+func (v *visitor) checkLiteral(lit *ast.BasicLit) {
+	srcText := v.textFor(lit)
+	text := literalText(lit)
+	callTexts, arg := v.literalCall()
+	v.runConfig.DebugLog("%s: literal %q, call %v, argument %d", v.runConfig.Fset.Position(lit.Pos()), text, callTexts, arg)
+	for _, p := range v.linter.patterns {
+		if p.kind() != kindLiteral || !p.re.MatchString(text) {
+			continue
+		}
+		if p.Call != "" && !p.matchesTexts(p.callRe, callTexts) {
+			continue
+		}
+		if p.Arg != nil && *p.Arg != arg {
+			continue
+		}
+		if !v.matchesEnclosingFunc(p) || !v.matchesContext(p) {
+			continue
+		}
+		if v.permit(lit) {
+			continue
+		}
+		v.issues = append(v.issues, UsedIssue{
+			identifier: srcText,
+			pattern:    p.re.String(),
+			pos:        lit.Pos(),
+			position:   v.runConfig.Fset.Position(lit.Pos()),
+			customMsg:  p.Msg,
+		})
+	}
+}
+
+// literalText returns the value of a string literal and the literal as it
+// appears in the source code for all other literals, for example 0o777.
+func literalText(lit *ast.BasicLit) string {
+	if lit.Kind == token.STRING {
+		if value, err := strconv.Unquote(lit.Value); err == nil {
+			return value
+		}
+	}
+	return lit.Value
+}
+
+// literalCall determines the function that gets called when the current
+// literal is passed directly as argument. It returns the match texts for the
+// function and the index of the argument, or nil and -1 when the literal is
+// not an argument.
+func (v *visitor) literalCall() ([]matchText, int) {
+	call, ok := v.parent().(*ast.CallExpr)
+	if !ok {
+		return nil, -1
+	}
+	for i, arg := range call.Args {
+		if ast.Unparen(arg) == v.stack[len(v.stack)-1] {
+			fun := ast.Unparen(call.Fun)
+			return v.expandMatchText(fun, v.textFor(fun)), i
+		}
+	}
+	return nil, -1
+}
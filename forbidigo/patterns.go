@@ -3,9 +3,12 @@ package forbidigo
 import (
 	"bytes"
 	"fmt"
+	"go/constant"
+	"go/token"
 	"io"
 	"regexp"
 	"regexp/syntax"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -13,7 +16,28 @@ import (
 
 // pattern matches code that is not supposed to be used.
 type pattern struct {
-	re, pkgRe *regexp.Regexp
+	re, pkgRe, callRe, keyRe, insideRe, notInsideRe *regexp.Regexp
+	paramsRe, resultsRe, argsRe, typeRe             *regexp.Regexp
+
+	// valueOp and value are the parsed form of Value.
+	valueOp token.Token
+	value   constant.Value
+
+	// Kind determines what the pattern gets matched against: identifiers
+	// (the default), compiler directives like `go:linkname` (directive),
+	// string and numeric literals (literal), the values in struct field
+	// tags (tag), the commands in `//go:generate` directives (generate) or
+	// types which get instantiated without using their constructor
+	// (construct), the types of operands of `==` and `!=` (compare) or the
+	// types involved in type assertions (assert) or calls of functions
+	// with certain signatures (signature), constant expressions with
+	// certain values (constant) or packages that form a layer of the
+	// architecture (layer).
+	Kind string `yaml:"kind,omitempty"`
+
+	// ID identifies the pattern in configuration files, where
+	// subdirectories may override or disable patterns by their ID.
+	ID string `yaml:"id,omitempty"`
 
 	// Pattern is the regular expression string that is used for matching.
 	// It gets matched against the literal source code text or the expanded
@@ -28,8 +52,172 @@ type pattern struct {
 	// Msg gets printed in addition to the normal message if a match is
 	// found.
 	Msg string `yaml:"msg,omitempty"`
+
+	// Alias determines whether the name of a type alias (only), the name
+	// of the type that it refers to (resolved) or both get matched. Empty
+	// is the same as both. Ignored unless the analyzer is configured to
+	// determine that information.
+	Alias string `yaml:"alias,omitempty"`
+
+	// Usage restricts matching to expressions which get called (call),
+	// method expressions like `(*T).Method` (expression) or any other use,
+	// for example method values (value). Empty matches all of them.
+	Usage string `yaml:"usage,omitempty"`
+
+	// Access restricts matching to expressions which get assigned to or
+	// incremented/decremented (write), have their address taken (address)
+	// or get read (read). Empty matches all of them.
+	Access string `yaml:"access,omitempty"`
+
+	// Discarded restricts matching to calls whose result gets ignored,
+	// i.e. expression statements, assignments to `_` and calls started by
+	// `go` or `defer`.
+	Discarded bool `yaml:"discarded,omitempty"`
+
+	// AllowOnly inverts the pattern: all identifiers from the packages
+	// matched by pkg are forbidden, except for those matched by p. Requires
+	// pkg and is only supported for identifier patterns.
+	AllowOnly bool `yaml:"allow_only,omitempty"`
+
+	// Implements is the full name of an interface, for example `io.Closer`
+	// or `net/http.Handler`, or a predeclared one like `error`. Only
+	// selectors like `x.Close` where the type of `x` implements the
+	// interface match. Only supported for identifier patterns.
+	Implements string `yaml:"implements,omitempty"`
+
+	// Call is a regular expression for the function that a literal gets
+	// passed to as argument. It gets matched like an identifier pattern.
+	// Only supported for literal patterns.
+	Call string `yaml:"call,omitempty"`
+
+	// Arg is the index of the argument that a literal gets passed as,
+	// starting at zero. Only supported for literal patterns.
+	Arg *int `yaml:"arg,omitempty"`
+
+	// Key is a regular expression for the key of a struct field tag, for
+	// example `json`. The value gets matched by Pattern. Only supported for
+	// tag patterns.
+	Key string `yaml:"key,omitempty"`
+
+	// Inside is a regular expression for the name of a function. Only
+	// matches inside such a function are reported. Functions are named
+	// like `Foo`, methods like `Type.Method` and function literals like
+	// `Foo.func1`. The initialization of a package-level variable counts as
+	// a function named after the variable. In addition, the name with the
+	// full package path as prefix gets matched, for example
+	// `example.com/some/pkg.Foo`.
+	Inside string `yaml:"inside,omitempty"`
+
+	// NotInside is like Inside, except that matches inside such a
+	// function are not reported.
+	NotInside string `yaml:"not_inside,omitempty"`
+
+	// Context restricts matches to code inside `go` statements (go),
+	// `defer` statements (defer), loops (loop), `init` functions (init) or
+	// the initialization of package-level variables (global_var).
+	Context string `yaml:"context,omitempty"`
+
+	// NotContext is like Context, except that matches in such code are not
+	// reported.
+	NotContext string `yaml:"not_context,omitempty"`
+
+	// Via is the constructor which must be used instead of creating a zero
+	// value of a type. It gets mentioned in the message if Msg is empty.
+	// Only supported for construct patterns.
+	Via string `yaml:"via,omitempty"`
+
+	// Params is a regular expression for the parameter types of a function
+	// that gets called, separated by a comma and a space, for example
+	// `context.Context, ...string`. Only supported for signature patterns.
+	Params string `yaml:"params,omitempty"`
+
+	// Results is like Params for the result types.
+	Results string `yaml:"results,omitempty"`
+
+	// Args is like Params for the types of the arguments that get passed.
+	Args string `yaml:"args,omitempty"`
+
+	// Const is the full name of a constant, for example
+	// `crypto/tls.VersionTLS10`. Expressions with the same value and type
+	// match. Only supported for constant patterns.
+	Const string `yaml:"const,omitempty"`
+
+	// Type is a regular expression for the type of a constant expression,
+	// matched like the type in a selector expression, for example
+	// `os.FileMode`. Only supported for constant patterns.
+	Type string `yaml:"type,omitempty"`
+
+	// Value is a constant that the value of a constant expression gets
+	// compared with, optionally prefixed with the comparison operator
+	// (==, !=, <, <=, >, >= or & for "has any of the bits set"), for example
+	// `>0o755`. The default operator is ==. Only supported for constant
+	// patterns.
+	Value string `yaml:"value,omitempty"`
+
+	// Name is the name of a layer, which gets used by Uses and in messages.
+	// Only supported for layer patterns, where it is required.
+	Name string `yaml:"name,omitempty"`
+
+	// Uses lists the names of the other layers that a layer may depend on.
+	// Only supported for layer patterns.
+	Uses []string `yaml:"uses,omitempty"`
 }
 
+// Supported values for pattern.Access.
+const (
+	accessRead    = "read"
+	accessWrite   = "write"
+	accessAddress = "address"
+)
+
+// Supported values for pattern.Kind.
+const (
+	kindIdentifier = "identifier"
+	kindDirective  = "directive"
+	kindLiteral    = "literal"
+	kindTag        = "tag"
+	kindGenerate   = "generate"
+	kindConstruct  = "construct"
+	kindCompare    = "compare"
+	kindAssert     = "assert"
+	kindSignature  = "signature"
+	kindConstant   = "constant"
+	kindLayer      = "layer"
+)
+
+// kinds lists all supported values for pattern.Kind.
+var kinds = []string{kindIdentifier, kindDirective, kindLiteral, kindTag, kindGenerate, kindConstruct, kindCompare, kindAssert, kindSignature, kindConstant, kindLayer}
+
+// kindFields lists the optional fields that are supported by each kind of
+// pattern.
+var kindFields = map[string][]string{
+	kindIdentifier: {"pkg", "alias", "usage", "access", "discarded", "allow_only", "implements", "inside", "not_inside", "context", "not_context"},
+	kindDirective:  {},
+	kindLiteral:    {"pkg", "alias", "call", "arg", "inside", "not_inside", "context", "not_context"},
+	kindTag:        {"key"},
+	kindGenerate:   {},
+	kindConstruct:  {"pkg", "alias", "via", "inside", "not_inside", "context", "not_context"},
+	kindCompare:    {"pkg", "alias", "inside", "not_inside", "context", "not_context"},
+	kindAssert:     {"pkg", "alias", "inside", "not_inside", "context", "not_context"},
+	kindSignature:  {"pkg", "alias", "params", "results", "args", "discarded", "inside", "not_inside", "context", "not_context"},
+	kindConstant:   {"pkg", "alias", "const", "type", "value", "inside", "not_inside", "context", "not_context"},
+	kindLayer:      {"pkg", "name", "uses"},
+}
+
+// Supported values for pattern.Alias.
+const (
+	aliasBoth     = "both"
+	aliasOnly     = "only"
+	aliasResolved = "resolved"
+)
+
+// Supported values for pattern.Usage.
+const (
+	usageCall       = "call"
+	usageValue      = "value"
+	usageExpression = "expression"
+)
+
 // A yamlPattern pattern in a YAML string may be represented either by a string
 // (the traditional regular expression syntax) or a struct (for more complex
 // patterns).
@@ -107,20 +295,200 @@ func (p *pattern) validate() error {
 	}
 	p.re = ptrnRe
 
-	if p.Package != "" {
-		pkgRe, err := regexp.Compile(p.Package)
+	for _, field := range []struct {
+		name string
+		expr *string
+		re   **regexp.Regexp
+	}{
+		{"package", &p.Package, &p.pkgRe},
+		{"call", &p.Call, &p.callRe},
+		{"key", &p.Key, &p.keyRe},
+		{"inside", &p.Inside, &p.insideRe},
+		{"not_inside", &p.NotInside, &p.notInsideRe},
+		{"params", &p.Params, &p.paramsRe},
+		{"results", &p.Results, &p.resultsRe},
+		{"args", &p.Args, &p.argsRe},
+		{"type", &p.Type, &p.typeRe},
+	} {
+		if *field.expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(*field.expr)
+		if err != nil {
+			return fmt.Errorf("unable to compile %s pattern `%s`: %s", field.name, *field.expr, err)
+		}
+		*field.re = re
+	}
+
+	switch p.Alias {
+	case "", aliasBoth, aliasOnly, aliasResolved:
+	default:
+		return fmt.Errorf("invalid alias mode `%s`, must be one of %s, %s or %s", p.Alias, aliasOnly, aliasResolved, aliasBoth)
+	}
+
+	switch p.Usage {
+	case "", usageCall, usageValue, usageExpression:
+	default:
+		return fmt.Errorf("invalid usage `%s`, must be one of %s, %s or %s", p.Usage, usageCall, usageValue, usageExpression)
+	}
+
+	switch p.Access {
+	case "", accessRead, accessWrite, accessAddress:
+	default:
+		return fmt.Errorf("invalid access `%s`, must be one of %s, %s or %s", p.Access, accessRead, accessWrite, accessAddress)
+	}
+
+	if p.Value != "" {
+		if p.Const != "" {
+			return fmt.Errorf("const and value cannot be combined")
+		}
+		op, value, err := parseValue(p.Value)
 		if err != nil {
-			return fmt.Errorf("unable to compile package pattern `%s`: %s", p.Package, err)
+			return fmt.Errorf("unable to parse value `%s`: %s", p.Value, err)
 		}
-		p.pkgRe = pkgRe
+		p.valueOp, p.value = op, value
+	}
+
+	for _, context := range []string{p.Context, p.NotContext} {
+		if context != "" && !slices.Contains(contexts, context) {
+			return fmt.Errorf("invalid context `%s`, must be one of %s", context, strings.Join(contexts, ", "))
+		}
+	}
+
+	supportedFields, ok := kindFields[p.kind()]
+	if !ok {
+		return fmt.Errorf("invalid kind `%s`, must be one of %s", p.Kind, strings.Join(kinds, ", "))
+	}
+	for _, field := range p.optionalFields() {
+		if field.set && !slices.Contains(supportedFields, field.name) {
+			return fmt.Errorf("%s is not supported for %s patterns", field.name, p.kind())
+		}
+	}
+	if p.kind() == kindLayer && p.Pattern != "" {
+		return fmt.Errorf("p is not supported for %s patterns", p.Kind)
+	}
+	if p.kind() == kindLayer && (p.Name == "" || p.Package == "") {
+		return fmt.Errorf("name and pkg are required for %s patterns", p.Kind)
+	}
+	if p.AllowOnly && p.Package == "" {
+		return fmt.Errorf("allow_only requires pkg")
+	}
+	if p.kind() == kindLiteral && p.Call == "" && (p.Package != "" || p.Alias != "" || p.Arg != nil) {
+		return fmt.Errorf("pkg, alias and arg require call for %s patterns", p.Kind)
 	}
 
 	return nil
 }
 
-func (p *pattern) matches(matchTexts []string) bool {
+// optionalFields returns the names of all optional fields which are
+// specific to certain kinds of patterns and whether they are set.
+func (p *pattern) optionalFields() []struct {
+	name string
+	set  bool
+} {
+	return []struct {
+		name string
+		set  bool
+	}{
+		{"pkg", p.Package != ""},
+		{"alias", p.Alias != ""},
+		{"usage", p.Usage != ""},
+		{"access", p.Access != ""},
+		{"discarded", p.Discarded},
+		{"allow_only", p.AllowOnly},
+		{"implements", p.Implements != ""},
+		{"call", p.Call != ""},
+		{"arg", p.Arg != nil},
+		{"key", p.Key != ""},
+		{"inside", p.Inside != ""},
+		{"not_inside", p.NotInside != ""},
+		{"context", p.Context != ""},
+		{"not_context", p.NotContext != ""},
+		{"via", p.Via != ""},
+		{"params", p.Params != ""},
+		{"results", p.Results != ""},
+		{"args", p.Args != ""},
+		{"const", p.Const != ""},
+		{"type", p.Type != ""},
+		{"value", p.Value != ""},
+		{"name", p.Name != ""},
+		{"uses", p.Uses != nil},
+	}
+}
+
+// kind returns the kind of the pattern, with the default filled in. Patterns
+// with const, type or value are constant patterns even without an explicit
+// kind.
+func (p *pattern) kind() string {
+	switch {
+	case p.Kind != "":
+		return p.Kind
+	case p.Const != "" || p.Type != "" || p.Value != "":
+		return kindConstant
+	default:
+		return kindIdentifier
+	}
+}
+
+// description describes the pattern in issues. For most patterns, this is
+// the regular expression. For kinds of patterns where that may be empty or
+// isn't what got matched, all regular expressions and values that are set
+// are listed.
+func (p *pattern) description() string {
+	switch {
+	case p.kind() == kindSignature, p.kind() == kindConstant:
+	case p.kind() == kindLayer:
+		return fmt.Sprintf("{name: %s, pkg: %s, uses: [%s]}", p.Name, p.Package, strings.Join(p.Uses, ", "))
+	case p.AllowOnly:
+		return fmt.Sprintf("{pkg: %s, p: %s, allow_only: true}", p.Package, p.Pattern)
+	default:
+		return p.re.String()
+	}
+	var parts []string
+	for _, field := range []struct{ name, value string }{
+		{"p", p.Pattern},
+		{"params", p.Params},
+		{"results", p.Results},
+		{"args", p.Args},
+		{"const", p.Const},
+		{"type", p.Type},
+		{"value", p.Value},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.name+": "+field.value)
+		}
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (p *pattern) matches(matchTexts []matchText) bool {
+	return p.matchesTexts(p.re, matchTexts)
+}
+
+// matchAll matches any text.
+var matchAll = regexp.MustCompile(``)
+
+// forbids checks whether an identifier pattern forbids an identifier. Normal
+// patterns forbid what they match, allowlists forbid everything from the
+// package that they don't match.
+func (p *pattern) forbids(matchTexts []matchText) bool {
+	if !p.AllowOnly {
+		return p.matches(matchTexts)
+	}
+	return p.matchesTexts(matchAll, matchTexts) && !p.matches(matchTexts)
+}
+
+// matchesTexts checks whether the regular expression matches one of the
+// texts, taking the package and alias mode of the pattern into account.
+func (p *pattern) matchesTexts(re *regexp.Regexp, matchTexts []matchText) bool {
 	for _, text := range matchTexts {
-		if p.re.MatchString(text) {
+		switch {
+		case text.alias && p.Alias == aliasResolved,
+			!text.alias && p.Alias == aliasOnly:
+			continue
+		}
+		if re.MatchString(text.text) &&
+			(p.Package == "" || p.pkgRe.MatchString(text.pkg)) {
 			return true
 		}
 	}
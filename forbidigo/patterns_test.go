@@ -91,6 +91,56 @@ func TestParseInvalidPattern_ReturnsError(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestParseInvalidKind_ReturnsError(t *testing.T) {
+	_, err := parse(`{p: ^go:linkname, kind: comment}`)
+	assert.EqualError(t, err, "invalid kind `comment`, must be one of identifier, directive, literal, tag, generate, construct, compare, assert, signature, constant, layer")
+}
+
+func TestParseDirectiveWithPackage_ReturnsError(t *testing.T) {
+	_, err := parse(`{p: ^go:linkname, kind: directive, pkg: ^runtime$}`)
+	assert.EqualError(t, err, "pkg is not supported for directive patterns")
+}
+
+func TestParseLiteralWithArgWithoutCall_ReturnsError(t *testing.T) {
+	_, err := parse(`{p: ^localhost:, kind: literal, arg: 0}`)
+	assert.EqualError(t, err, "pkg, alias and arg require call for literal patterns")
+}
+
+func TestParseConstWithValue_ReturnsError(t *testing.T) {
+	_, err := parse(`{const: crypto/tls.VersionTLS10, value: 0x0301}`)
+	assert.EqualError(t, err, "const and value cannot be combined")
+}
+
+func TestParseAllowOnlyWithoutPackage_ReturnsError(t *testing.T) {
+	_, err := parse(`{p: ^unsafe\.Sizeof$, allow_only: true}`)
+	assert.EqualError(t, err, "allow_only requires pkg")
+}
+
+func TestParseLayerWithoutName_ReturnsError(t *testing.T) {
+	_, err := parse(`{kind: layer, pkg: ^example\.com/app/domain(/|$)}`)
+	assert.EqualError(t, err, "name and pkg are required for layer patterns")
+}
+
+func TestParseLayerWithPattern_ReturnsError(t *testing.T) {
+	_, err := parse(`{kind: layer, name: domain, pkg: ^example\.com/app/domain(/|$), p: ^fmt\.}`)
+	assert.EqualError(t, err, "p is not supported for layer patterns")
+}
+
+func TestNewLinterWithUnknownLayer_ReturnsError(t *testing.T) {
+	_, err := NewLinter([]string{`{kind: layer, name: domain, pkg: ^example\.com/app/domain(/|$), uses: [model]}`})
+	assert.EqualError(t, err, "layer `domain` uses unknown layer `model`")
+}
+
+func TestParseInvalidContext_ReturnsError(t *testing.T) {
+	_, err := parse(`{p: ^time\.After$, context: select}`)
+	assert.EqualError(t, err, "invalid context `select`, must be one of go, defer, loop, init, global_var")
+}
+
+func TestParseInvalidAliasMode_ReturnsError(t *testing.T) {
+	_, err := parse(`{p: ^legacy\.Client$, alias: sometimes}`)
+	assert.EqualError(t, err, "invalid alias mode `sometimes`, must be one of only, resolved or both")
+}
+
 func TestUnmarshalYAML(t *testing.T) {
 	for _, tc := range []struct {
 		name            string
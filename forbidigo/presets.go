@@ -0,0 +1,40 @@
+package forbidigo
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// presetFiles contains the presets which may be listed in Extends. Each of
+// them is a configuration file named after the preset. All patterns have an
+// ID so that they can be disabled or overridden individually.
+//
+//go:embed presets/*.yml
+var presetFiles embed.FS
+
+// Presets returns the names of all presets.
+func Presets() []string {
+	entries, _ := fs.ReadDir(presetFiles, "presets")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yml"))
+	}
+	return names
+}
+
+// loadPreset loads a preset by its name.
+func loadPreset(name string) (*Config, error) {
+	file, err := presetFiles.Open(path.Join("presets", name+".yml"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown preset `%s`, must be one of %s", name, strings.Join(Presets(), ", "))
+	}
+	defer file.Close()
+	config, err := decodeConfig("preset "+name, file)
+	if err != nil {
+		return nil, err
+	}
+	return config.extend("", []string{"preset " + name})
+}
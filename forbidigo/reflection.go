@@ -0,0 +1,161 @@
+package forbidigo
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// reflectLookups are the methods of reflect.Value and reflect.Type which
+// look up a method or field by name.
+var reflectLookups = map[string]bool{
+	"MethodByName": true,
+	"FieldByName":  true,
+}
+
+// checkReflection treats calls like `reflect.ValueOf(db).MethodByName("Exec")`
+// like a use of `db.Exec`: when the name is a constant and the type of the
+// value that gets reflected is known statically, the text for the method or
+// field gets matched against the patterns for identifiers.
+func (v *visitor) checkReflection(call *ast.CallExpr) {
+	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil || len(call.Args) != 1 {
+		return
+	}
+	selector, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || !reflectLookups[selector.Sel.Name] || !isReflectType(v.runConfig.TypesInfo.TypeOf(selector.X)) {
+		return
+	}
+	name := v.runConfig.TypesInfo.Types[call.Args[0]].Value
+	if name == nil || name.Kind() != constant.String {
+		return
+	}
+	t := v.reflectedType(selector.X, map[types.Object]bool{})
+	if t == nil {
+		return
+	}
+	matchTexts, ok := v.selectorTexts(t, constant.StringVal(name))
+	if !ok {
+		return
+	}
+	usage := usageCall
+	if selector.Sel.Name == "FieldByName" {
+		usage = usageValue
+	}
+	srcText := v.textFor(call)
+	v.runConfig.DebugLog("%s: reflection %v", v.runConfig.Fset.Position(call.Pos()), matchTexts)
+	v.reportIdentifier(call, srcText, matchTexts, usage, accessRead, false)
+}
+
+// reflectedType determines the static type of the value behind a
+// reflect.Value or reflect.Type expression. It understands
+// `reflect.ValueOf(x)`, `reflect.TypeOf(x)`, `reflect.Indirect(v)` and
+// `v.Elem()` as well as variables which get initialized with those and are
+// never assigned again. It returns nil for everything else. Visited holds
+// the variables whose initial values are being followed, so that cycles
+// like `var a = reflect.Indirect(b)` and `var b = reflect.Indirect(a)`
+// end.
+func (v *visitor) reflectedType(expr ast.Expr, visited map[types.Object]bool) types.Type {
+	if ident, ok := ast.Unparen(expr).(*ast.Ident); ok {
+		object := v.runConfig.TypesInfo.Uses[ident]
+		init := v.reflectVarInits()[object]
+		if init == nil || visited[object] {
+			return nil
+		}
+		visited[object] = true
+		return v.reflectedType(init, visited)
+	}
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	var fun *ast.Ident
+	var recv ast.Expr
+	switch f := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		fun = f
+	case *ast.SelectorExpr:
+		fun = f.Sel
+		if _, isMethod := v.runConfig.TypesInfo.Selections[f]; isMethod {
+			recv = f.X
+		}
+	default:
+		return nil
+	}
+	function, ok := v.runConfig.TypesInfo.Uses[fun].(*types.Func)
+	if !ok || function.Pkg() == nil || function.Pkg().Path() != "reflect" {
+		return nil
+	}
+	switch {
+	case recv == nil && len(call.Args) == 1 && (function.Name() == "ValueOf" || function.Name() == "TypeOf"):
+		return v.runConfig.TypesInfo.TypeOf(call.Args[0])
+	case recv == nil && len(call.Args) == 1 && function.Name() == "Indirect":
+		return indirect(v.reflectedType(call.Args[0], visited))
+	case recv != nil && len(call.Args) == 0 && function.Name() == "Elem":
+		return indirect(v.reflectedType(recv, visited))
+	default:
+		return nil
+	}
+}
+
+// reflectVarInits returns the initial values of the variables in the root
+// node which have type reflect.Value or reflect.Type, collecting them first
+// if necessary. Variables which get assigned again are left out because
+// their value depends on the control flow.
+func (v *visitor) reflectVarInits() map[types.Object]ast.Expr {
+	if v.reflectVars != nil {
+		return v.reflectVars
+	}
+	v.reflectVars = map[types.Object]ast.Expr{}
+	define := func(ident *ast.Ident, value ast.Expr) {
+		if object := v.runConfig.TypesInfo.Defs[ident]; object != nil && isReflectType(object.Type()) {
+			v.reflectVars[object] = value
+		}
+	}
+	reassigned := map[types.Object]bool{}
+	ast.Inspect(v.root, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if object := v.runConfig.TypesInfo.Uses[ident]; object != nil {
+					reassigned[object] = true
+				} else if node.Tok == token.DEFINE && len(node.Lhs) == len(node.Rhs) {
+					define(ident, node.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(node.Names) == len(node.Values) {
+				for i, name := range node.Names {
+					define(name, node.Values[i])
+				}
+			}
+		}
+		return true
+	})
+	for object := range reassigned {
+		delete(v.reflectVars, object)
+	}
+	return v.reflectVars
+}
+
+// indirect returns the element type of pointers and the type itself for
+// everything else.
+func indirect(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+// isReflectType checks whether the type is reflect.Value or reflect.Type.
+func isReflectType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "reflect" {
+		return false
+	}
+	return named.Obj().Name() == "Value" || named.Obj().Name() == "Type"
+}
@@ -0,0 +1,136 @@
+package forbidigo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:generate go run ./internal/schemagen -o schema.json
+
+// schemaJSON is the JSON Schema for configuration files, generated from the
+// Config, Layer and pattern structs.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// Schema returns the JSON Schema for configuration files.
+func Schema() []byte {
+	return schemaJSON
+}
+
+// schema is the subset of JSON Schema which gets generated for
+// configuration files.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Enum                 []string           `json:"enum"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	OneOf                []*schema          `json:"oneOf"`
+	Defs                 map[string]*schema `json:"$defs"`
+}
+
+// configSchema is the parsed form of schemaJSON.
+var configSchema = func() *schema {
+	var s schema
+	if err := json.Unmarshal(schemaJSON, &s); err != nil {
+		panic(fmt.Sprintf("invalid schema: %v", err))
+	}
+	return &s
+}()
+
+// schemaError is a violation of the schema at a certain position.
+type schemaError struct {
+	line, column int
+	msg          string
+}
+
+func (e schemaError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.line, e.column, e.msg)
+}
+
+// validateSchema checks a YAML document against the schema for
+// configuration files.
+func validateSchema(node *yaml.Node) []schemaError {
+	return configSchema.validate(node)
+}
+
+func (s *schema) validate(node *yaml.Node) []schemaError {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return s.validate(node.Content[0])
+	case yaml.AliasNode:
+		return s.validate(node.Alias)
+	}
+	if s.Ref != "" {
+		return configSchema.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")].validate(node)
+	}
+	if s.OneOf != nil {
+		types := make([]string, 0, len(s.OneOf))
+		for _, alternative := range s.OneOf {
+			if alternative.hasType(node) {
+				return alternative.validate(node)
+			}
+			types = append(types, alternative.Type)
+		}
+		return []schemaError{newSchemaError(node, "must be %s", strings.Join(types, " or "))}
+	}
+	if !s.hasType(node) {
+		return []schemaError{newSchemaError(node, "must be %s", s.Type)}
+	}
+
+	var errs []schemaError
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if s.Enum != nil && !slices.Contains(s.Enum, node.Value) {
+			errs = append(errs, newSchemaError(node, "invalid value `%s`, must be one of %s", node.Value, strings.Join(s.Enum, ", ")))
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			errs = append(errs, s.Items.validate(item)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			property, ok := s.Properties[key.Value]
+			if !ok {
+				errs = append(errs, newSchemaError(key, "unknown field `%s`", key.Value))
+				continue
+			}
+			errs = append(errs, property.validate(value)...)
+		}
+	}
+	return errs
+}
+
+// hasType checks whether the kind of YAML node fits the type in the schema.
+// Like when decoding YAML into strings, any scalar is accepted as string.
+func (s *schema) hasType(node *yaml.Node) bool {
+	switch s.Type {
+	case "object":
+		return node.Kind == yaml.MappingNode
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	case "string":
+		return node.Kind == yaml.ScalarNode && node.Tag != "!!null"
+	case "boolean":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case "integer":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	default:
+		return true
+	}
+}
+
+func newSchemaError(node *yaml.Node, format string, args ...interface{}) schemaError {
+	return schemaError{line: node.Line, column: node.Column, msg: fmt.Sprintf(format, args...)}
+}
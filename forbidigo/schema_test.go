@@ -0,0 +1,69 @@
+package forbidigo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	cmd := exec.Command("go", "run", "./internal/schemagen", "-o", path)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	generated, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(Schema()), "schema.json is outdated, run go generate")
+}
+
+func TestLoadConfigWithSchemaViolations_ReturnsError(t *testing.T) {
+	for _, tc := range []struct {
+		name, content, expectedErr string
+	}{
+		{
+			name: "unknown pattern field",
+			content: `patterns:
+  - ^fmt\.Println$
+  - {p: ^os\.Exit$, mgs: return an error}
+`,
+			expectedErr: "3:21: unknown field `mgs`",
+		},
+		{
+			name: "invalid kind",
+			content: `patterns:
+  - p: ^unsafe\.
+    kind: identifer
+`,
+			expectedErr: "3:11: invalid value `identifer`, must be one of identifier, directive, literal, tag, generate, construct, compare, assert, signature, constant, layer",
+		},
+		{
+			name:        "wrong types",
+			content:     "tests: yes please\npatterns: ^fmt\\.Println$\n",
+			expectedErr: "1:8: must be boolean\nconfig.yml:2:11: must be array",
+		},
+		{
+			name:        "pattern is neither string nor struct",
+			content:     "patterns: [[^fmt\\.Println$]]\n",
+			expectedErr: "1:12: must be string or object",
+		},
+		{
+			name: "invalid pattern",
+			content: `patterns:
+  - ^fmt\.Println$
+  - {p: ^os\.Exit$, kind: directive, pkg: ^os$}
+`,
+			expectedErr: "3:5: pkg is not supported for directive patterns",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decodeConfig("config.yml", strings.NewReader(tc.content))
+			require.Error(t, err)
+			assert.Equal(t, "config.yml:"+tc.expectedErr, err.Error())
+		})
+	}
+}
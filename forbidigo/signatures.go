@@ -0,0 +1,90 @@
+package forbidigo
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// checkSignature matches calls against the patterns for signature. The
+// function that gets called is matched like an identifier by the pattern
+// itself, its parameters, its results and the types of the actual
+// arguments by separate regular expressions.
+func (v *visitor) checkSignature(call *ast.CallExpr) {
+	if !v.cfg.AnalyzeTypes || v.runConfig.TypesInfo == nil {
+		return
+	}
+	fun := ast.Unparen(call.Fun)
+	if typeAndValue, ok := v.runConfig.TypesInfo.Types[fun]; !ok || typeAndValue.IsType() || typeAndValue.IsBuiltin() {
+		// Conversions and builtins don't have a signature.
+		return
+	}
+	signature, ok := v.runConfig.TypesInfo.TypeOf(fun).Underlying().(*types.Signature)
+	if !ok {
+		return
+	}
+
+	srcText := v.textFor(fun)
+	var matchTexts []matchText
+	switch fun.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		matchTexts = v.expandMatchText(fun, srcText)
+	default:
+		matchTexts = []matchText{{text: srcText}}
+	}
+	params := v.tupleText(signature.Params(), signature.Variadic())
+	results := v.tupleText(signature.Results(), false)
+	argTypes := make([]string, 0, len(call.Args))
+	for _, arg := range call.Args {
+		argTypes = append(argTypes, v.typeString(v.runConfig.TypesInfo.TypeOf(arg)))
+	}
+	args := strings.Join(argTypes, ", ")
+	v.runConfig.DebugLog("%s: signature %v, params %q, results %q, args %q", v.runConfig.Fset.Position(call.Pos()), matchTexts, params, results, args)
+
+	for _, p := range v.linter.patterns {
+		if p.kind() != kindSignature ||
+			!p.matches(matchTexts) ||
+			(p.Params != "" && !p.paramsRe.MatchString(params)) ||
+			(p.Results != "" && !p.resultsRe.MatchString(results)) ||
+			(p.Args != "" && !p.argsRe.MatchString(args)) ||
+			(p.Discarded && !v.resultDiscarded(len(v.stack)-1)) ||
+			!v.matchesEnclosingFunc(p) ||
+			!v.matchesContext(p) ||
+			v.permitText(fun.Pos(), srcText) {
+			continue
+		}
+		v.issues = append(v.issues, UsedIssue{
+			identifier: srcText,
+			pattern:    p.description(),
+			pos:        fun.Pos(),
+			position:   v.runConfig.Fset.Position(fun.Pos()),
+			customMsg:  p.Msg,
+		})
+	}
+}
+
+// tupleText returns the types in a parameter or result list, separated by
+// a comma and a space, for example `context.Context, ...string`.
+func (v *visitor) tupleText(tuple *types.Tuple, variadic bool) string {
+	texts := make([]string, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		t := tuple.At(i).Type()
+		if variadic && i == tuple.Len()-1 {
+			if slice, ok := t.(*types.Slice); ok {
+				texts = append(texts, "..."+v.typeString(slice.Elem()))
+				continue
+			}
+		}
+		texts = append(texts, v.typeString(t))
+	}
+	return strings.Join(texts, ", ")
+}
+
+// typeString returns a type with package names instead of package paths, for
+// example `*sql.DB`.
+func (v *visitor) typeString(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	return types.TypeString(t, func(pkg *types.Package) string { return pkg.Name() })
+}
@@ -0,0 +1,88 @@
+package forbidigo
+
+import (
+	"go/ast"
+	"strconv"
+)
+
+// checkTag matches the key/value pairs in a struct field tag against the
+// patterns for tags.
+func (v *visitor) checkTag(tag *ast.BasicLit) {
+	value, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return
+	}
+	for _, field := range parseStructTag(value) {
+		v.runConfig.DebugLog("%s: tag key %q, value %q", v.runConfig.Fset.Position(tag.Pos()), field.key, field.value)
+		text := field.key + ":" + strconv.Quote(field.value)
+		for _, p := range v.linter.patterns {
+			if p.kind() != kindTag ||
+				(p.Key != "" && !p.keyRe.MatchString(field.key)) ||
+				!p.re.MatchString(field.value) ||
+				v.permitText(tag.Pos(), text) {
+				continue
+			}
+			v.issues = append(v.issues, UsedIssue{
+				identifier: text,
+				pattern:    p.re.String(),
+				pos:        tag.Pos(),
+				position:   v.runConfig.Fset.Position(tag.Pos()),
+				customMsg:  p.Msg,
+			})
+		}
+	}
+}
+
+type structTagField struct {
+	key, value string
+}
+
+// parseStructTag splits a struct tag into its key/value pairs. It follows
+// the conventions implemented by reflect.StructTag.Lookup and stops at the
+// first malformed pair.
+func parseStructTag(tag string) []structTagField {
+	var fields []structTagField
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		quotedValue := tag[:i+1]
+		tag = tag[i+1:]
+
+		value, err := strconv.Unquote(quotedValue)
+		if err != nil {
+			break
+		}
+		fields = append(fields, structTagField{key: key, value: value})
+	}
+	return fields
+}
@@ -0,0 +1,14 @@
+package forbidigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStructTag(t *testing.T) {
+	assert.Equal(t, []structTagField{
+		{key: "json", value: "a,omitempty"},
+		{key: "xml", value: `with "quotes"`},
+	}, parseStructTag(`json:"a,omitempty"  xml:"with \"quotes\"" malformed`))
+}
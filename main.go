@@ -2,9 +2,14 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"go/ast"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/ashanbrown/forbidigo/v2/forbidigo"
 	"golang.org/x/tools/go/packages"
@@ -13,12 +18,62 @@ import (
 func main() {
 	log.SetFlags(0) // remove log timestamp
 
+	// Subcommands come before flags and patterns. check-config accepts the
+	// same flags and patterns as linting.
+	checkConfig := false
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "schema":
+			if _, err := os.Stdout.Write(forbidigo.Schema()); err != nil {
+				log.Fatalf("Could not write schema: %s", err)
+			}
+			return
+		case "check-config":
+			checkConfig = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		}
+	}
+
 	setExitStatus := flag.Bool("set_exit_status", false, "Set exit status to 1 if any issues are found")
 	includeTests := flag.Bool("tests", true, "Include tests")
 	excludeGodocExamples := flag.Bool("exclude_godoc_examples", true, "Exclude code in godoc examples")
 	analyzeTypes := flag.Bool("analyze_types", false, "Replace the literal source code based on the semantic of the code before matching against patterns")
+	analyzeReflection := flag.Bool("analyze_reflection", false, "Match methods and fields that get looked up by constant names via reflect, requires -analyze_types")
+	configPath := flag.String("config", "", "Read patterns and options from a YAML or JSON file instead of the "+forbidigo.ConfigFileName+" files in the module")
 	flag.Parse()
 
+	// Without -config, each package uses the configuration files in its
+	// directory and its parents. The one for the current directory
+	// determines how packages get loaded.
+	var config *forbidigo.Config
+	var err error
+	if *configPath != "" {
+		config, err = forbidigo.LoadConfig(*configPath)
+	} else {
+		config, err = forbidigo.LoadDirConfig(".")
+	}
+	if err != nil {
+		log.Fatalf("Could not load config: %s", err)
+	}
+	if config == nil {
+		config = &forbidigo.Config{}
+	}
+
+	// Options from config files apply unless the flags are set explicitly.
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	for name, value := range map[string]*bool{
+		"set_exit_status":        config.SetExitStatus,
+		"tests":                  config.Tests,
+		"exclude_godoc_examples": config.ExcludeGodocExamples,
+		"analyze_types":          config.AnalyzeTypes,
+		"analyze_reflection":     config.AnalyzeReflection,
+	} {
+		if value != nil && !setFlags[name] {
+			_ = flag.Set(name, strconv.FormatBool(*value))
+		}
+	}
+
 	var patterns = []string(nil)
 
 	firstPkg := 0
@@ -30,16 +85,89 @@ func main() {
 		patterns = append(patterns, arg)
 	}
 
-	if patterns == nil {
-		patterns = forbidigo.DefaultPatterns()
+	var options []forbidigo.Option
+	if setFlags["exclude_godoc_examples"] {
+		options = append(options, forbidigo.OptionExcludeGodocExamples(*excludeGodocExamples))
 	}
-	options := []forbidigo.Option{
-		forbidigo.OptionExcludeGodocExamples(*excludeGodocExamples),
-		forbidigo.OptionAnalyzeTypes(*analyzeTypes),
+	if setFlags["analyze_types"] {
+		options = append(options, forbidigo.OptionAnalyzeTypes(*analyzeTypes))
 	}
-	linter, err := forbidigo.NewLinter(patterns, options...)
-	if err != nil {
-		log.Fatalf("Could not create linter: %s", err)
+	if setFlags["analyze_reflection"] {
+		options = append(options, forbidigo.OptionAnalyzeReflection(*analyzeReflection))
+	}
+	linters := map[string]*forbidigo.Linter{}
+	linterFor := func(dir string) (*forbidigo.Linter, error) {
+		if *configPath != "" {
+			dir = ""
+		}
+		if linter, ok := linters[dir]; ok {
+			return linter, nil
+		}
+		dirConfig := config
+		if dir != "" {
+			dirConfig, err = forbidigo.LoadDirConfig(dir)
+			if err != nil {
+				return nil, fmt.Errorf("could not load config: %w", err)
+			}
+			if dirConfig == nil {
+				dirConfig = &forbidigo.Config{}
+			}
+			// Packages get loaded according to the configuration for
+			// the current directory.
+			if dirConfig.Tests != nil && *dirConfig.Tests != *includeTests && !setFlags["tests"] {
+				return nil, fmt.Errorf("config for %s: tests is %t, but packages get loaded with tests %t because of the config for the current directory", dir, *dirConfig.Tests, *includeTests)
+			}
+			if dirConfig.AnalyzeTypes != nil && *dirConfig.AnalyzeTypes && !*analyzeTypes && !setFlags["analyze_types"] {
+				return nil, fmt.Errorf("config for %s: analyze_types is enabled, but packages get loaded without type information because it is not enabled for the current directory", dir)
+			}
+		}
+		linter, err := dirConfig.NewLinter(patterns, options...)
+		if err != nil {
+			return nil, fmt.Errorf("could not create linter: %w", err)
+		}
+		linters[dir] = linter
+		return linter, nil
+	}
+
+	if checkConfig {
+		// Besides the configuration for the current directory, those for
+		// subdirectories get checked.
+		dirs := []string{""}
+		if *configPath == "" {
+			_ = filepath.WalkDir(".", func(path string, entry fs.DirEntry, err error) error {
+				switch {
+				case err != nil:
+				case entry.IsDir() && path != "." && (strings.HasPrefix(entry.Name(), ".") || strings.HasPrefix(entry.Name(), "_") || entry.Name() == "testdata" || entry.Name() == "vendor"):
+					// Like the go command, ignore these directories.
+					return fs.SkipDir
+				case !entry.IsDir() && entry.Name() == forbidigo.ConfigFileName && path != forbidigo.ConfigFileName:
+					dirs = append(dirs, filepath.Dir(path))
+				}
+				return nil
+			})
+		}
+		var problems []string
+		for _, dir := range dirs {
+			prefix := ""
+			if dir != "" {
+				prefix = dir + ": "
+			}
+			linter, err := linterFor(dir)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			for _, problem := range linter.Check() {
+				problems = append(problems, prefix+problem)
+			}
+		}
+		for _, problem := range problems {
+			log.Println(problem)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
 	cfg := packages.Config{
@@ -62,7 +190,15 @@ func main() {
 		for _, n := range p.Syntax {
 			nodes = append(nodes, n)
 		}
-		newIssues, err := linter.RunWithConfig(forbidigo.RunConfig{Fset: p.Fset, TypesInfo: p.TypesInfo}, nodes...)
+		dir := ""
+		if len(p.GoFiles) > 0 {
+			dir = filepath.Dir(p.GoFiles[0])
+		}
+		linter, err := linterFor(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		newIssues, err := linter.RunWithConfig(forbidigo.RunConfig{Fset: p.Fset, TypesInfo: p.TypesInfo, PkgPath: p.PkgPath, Pkg: p.Types}, nodes...)
 		if err != nil {
 			log.Fatalf("failed: %s", err)
 		}
@@ -31,6 +31,7 @@ type analyzer struct {
 	usePermitDirective bool
 	includeExamples    bool
 	analyzeTypes       bool
+	analyzeReflection  bool
 	debugLog           func(format string, args ...interface{})
 }
 
@@ -54,6 +55,7 @@ func newAnalyzer(debugLog func(format string, args ...interface{})) *analysis.An
 	flags.BoolVar(&a.includeExamples, "examples", false, "check godoc examples")
 	flags.BoolVar(&a.usePermitDirective, "permit", true, `when set, lines with "//permit" directives will be ignored`)
 	flags.BoolVar(&a.analyzeTypes, "analyze_types", false, `when set, expressions get expanded instead of matching the literal source code`)
+	flags.BoolVar(&a.analyzeReflection, "analyze_reflection", false, `when set together with analyze_types, methods and fields looked up by constant names via reflect get matched`)
 	return &analysis.Analyzer{
 		Name:  "forbidigo",
 		Doc:   "forbid identifiers",
@@ -70,6 +72,7 @@ func (a *analyzer) runAnalysis(pass *analysis.Pass) (interface{}, error) {
 		forbidigo.OptionIgnorePermitDirectives(!a.usePermitDirective),
 		forbidigo.OptionExcludeGodocExamples(!a.includeExamples),
 		forbidigo.OptionAnalyzeTypes(a.analyzeTypes),
+		forbidigo.OptionAnalyzeReflection(a.analyzeReflection),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure linter: %w", err)
@@ -78,7 +81,7 @@ func (a *analyzer) runAnalysis(pass *analysis.Pass) (interface{}, error) {
 	for _, f := range pass.Files {
 		nodes = append(nodes, f)
 	}
-	config := forbidigo.RunConfig{Fset: pass.Fset, DebugLog: a.debugLog}
+	config := forbidigo.RunConfig{Fset: pass.Fset, PkgPath: pass.Pkg.Path(), Pkg: pass.Pkg, DebugLog: a.debugLog}
 	if a.analyzeTypes {
 		config.TypesInfo = pass.TypesInfo
 	}
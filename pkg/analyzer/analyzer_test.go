@@ -37,6 +37,9 @@ func TestExpandAnalyzer(t *testing.T) {
 		`{p: renamed\.Forbidden, pkg: ^example.com/some/renamedpkg$}`,
 		`{p: renamed\.Struct.Forbidden, pkg: ^example.com/some/renamedpkg$}`,
 		`{p: ^error\.Error$}`,
+		`{p: '^struct\{Println string\}\.Println$'}`,
+		`{p: ^testdata\.Local\.localType\.Forbidden$, pkg: ^expandtext$}`,
+		`{p: ^testdata\.Local\.func1\.localType\.Forbidden$, pkg: ^expandtext$}`,
 	)
 	a := newAnalyzer(t.Logf)
 	for _, pattern := range patterns {
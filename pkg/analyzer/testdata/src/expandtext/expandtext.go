@@ -79,5 +79,19 @@ func Bar() string {
 	fmt := struct {
 		Println string
 	}{}
-	return fmt.Println // not matched because it expands to `struct{Println string}.Println`
+	return fmt.Println // want "fmt.Println.*forbidden by pattern.*struct.*Println"
+}
+
+func Local() {
+	type localType struct {
+		Forbidden int
+	}
+	_ = localType{}.Forbidden // want "localType...Forbidden.*forbidden by pattern.*Local..localType"
+
+	func() {
+		type localType struct {
+			Forbidden int
+		}
+		_ = localType{}.Forbidden // want "localType...Forbidden.*forbidden by pattern.*Local..func1..localType"
+	}()
 }